@@ -0,0 +1,61 @@
+// Copyright Contributors to the Open Cluster Management project
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// ConversionDataAnnotation holds the fields of a v1 object that an older API version cannot represent, so that they
+// are restored when the object is converted back to v1.
+const ConversionDataAnnotation = "discovery.open-cluster-management.io/conversion-data"
+
+// Hub marks DiscoveredCluster as the hub type that the other versions are converted to and from.
+func (*DiscoveredCluster) Hub() {}
+
+// Hub marks DiscoveryConfig as the hub type that the other versions are converted to and from.
+func (*DiscoveryConfig) Hub() {}
+
+// ConvertedCRDNames are the names of the CustomResourceDefinitions served in several versions, which are converted by
+// the conversion webhook.
+var ConvertedCRDNames = []string{
+	"discoveredclusters.discovery.open-cluster-management.io",
+	"discoveryconfigs.discovery.open-cluster-management.io",
+}
+
+// CustomResourceConversion returns the conversion of the CustomResourceDefinitions served in several versions, with the
+// conversion webhook linked to a service in the provided namespace
+func CustomResourceConversion(namespace string) *apiextensionsv1.CustomResourceConversion {
+	path := "/convert"
+	return &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Name:      "discovery-operator-webhook-service",
+					Namespace: namespace,
+					Path:      &path,
+				},
+			},
+			ConversionReviewVersions: []string{
+				"v1",
+				"v1beta1",
+			},
+		},
+	}
+}
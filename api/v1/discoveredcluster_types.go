@@ -18,6 +18,8 @@ limitations under the License.
 package v1
 
 import (
+	"slices"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -38,10 +40,41 @@ const (
 	// ImportStrategyAnnotation is the annotation indicating the import strategy.
 	ImportStrategyAnnotation = "discovery.open-cluster-management.io/import-strategy"
 
+	/*
+		ReimportAnnotation is the annotation requesting that a DiscoveredCluster whose ManagedCluster was deleted is
+		imported again. Its value is the time of the request in RFC 3339 format, so that each request is processed once.
+	*/
+	ReimportAnnotation = "discovery.open-cluster-management.io/reimport"
+
+	/*
+		ImportPriorityAnnotation is the annotation setting the import priority of a DiscoveredCluster. Clusters with a
+		higher integer priority are imported first when the import queue uses priority ordering.
+	*/
+	ImportPriorityAnnotation = "discovery.open-cluster-management.io/import-priority"
+
+	/*
+		ManagedClusterNameSuffixAnnotation is the annotation that disables suffixing the ManagedCluster name with the
+		cluster ID when it is set to "false" on a DiscoveredCluster or DiscoveryConfig. Clusters whose name is already
+		used are then not imported.
+	*/
+	ManagedClusterNameSuffixAnnotation = "discovery.open-cluster-management.io/managed-cluster-name-suffix"
+
 	// ImportCleanUpFinalizer is a cleanup finalizer associated with resources created by the discovery operator.
 	ImportCleanUpFinalizer = "discovery.open-cluster-management.io/import-cleanup"
 )
 
+// Import strategies that can be set with the ImportStrategyAnnotation on a DiscoveredCluster or DiscoveryConfig.
+const (
+	// ImportStrategyAutomatic imports the cluster as soon as the import prerequisites are met.
+	ImportStrategyAutomatic = "Automatic"
+
+	// ImportStrategyManual creates the import prerequisites and waits for the import to be approved.
+	ImportStrategyManual = "Manual"
+
+	// ImportStrategyDisabled prevents the cluster from being imported.
+	ImportStrategyDisabled = "Disabled"
+)
+
 // DiscoveredClusterSpec defines the desired state of DiscoveredCluster
 type DiscoveredClusterSpec struct {
 	// ActivityTimestamp records the last observed activity of the cluster.
@@ -65,10 +98,24 @@ type DiscoveredClusterSpec struct {
 	// DisplayName is a human-readable name assigned to the cluster.
 	DisplayName string `json:"displayName" yaml:"displayName"`
 
+	// ImportCredential references a Secret in the namespace of the DiscoveredCluster that is used to import
+	// self-managed clusters (e.g. OCP, OSD, ARO). The Secret contains either a kubeconfig key, or token and server keys.
+	// +optional
+	ImportCredential *corev1.LocalObjectReference `json:"importCredential,omitempty" yaml:"importCredential,omitempty"`
+
 	// ImportAsManagedCluster determines whether the discovered cluster should be automatically imported as a managed cluster.
 	// +kubebuilder:default:=false
 	ImportAsManagedCluster bool `json:"importAsManagedCluster,omitempty" yaml:"importAsManagedCluster,omitempty"`
 
+	// ImportApproved approves the import of a discovered cluster with the Manual import strategy.
+	// +optional
+	ImportApproved bool `json:"importApproved,omitempty" yaml:"importApproved,omitempty"`
+
+	// ManagedCluster overrides the settings of the DiscoveryConfig for the ManagedCluster created to import the
+	// cluster.
+	// +optional
+	ManagedCluster *ManagedClusterSettings `json:"managedCluster,omitempty" yaml:"managedCluster,omitempty"`
+
 	// IsManagedCluster indicates whether the cluster is currently managed.
 	IsManagedCluster bool `json:"isManagedCluster" yaml:"isManagedCluster"`
 
@@ -99,6 +146,9 @@ type DiscoveredClusterSpec struct {
 	// Status represents the current state of the discovered cluster (e.g Active, Stale).
 	Status string `json:"status,omitempty" yaml:"status,omitempty"`
 
+	// TrialEndDate is the date on which the trial subscription of the cluster ends, if it is a trial.
+	TrialEndDate *metav1.Time `json:"trialEndDate,omitempty" yaml:"trialEndDate,omitempty"`
+
 	// Type defines the type of cluster, such as OpenShift, Kubernetes, or a specific managed service type.
 	Type string `json:"type" yaml:"type"`
 
@@ -132,13 +182,53 @@ type DiscoveredClusterCondition struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
+// Subscription statuses reported by OCM for a DiscoveredCluster
+const (
+	SubscriptionStatusActive        string = "Active"
+	SubscriptionStatusArchived      string = "Archived"
+	SubscriptionStatusDeprovisioned string = "Deprovisioned"
+	SubscriptionStatusDisconnected  string = "Disconnected"
+	SubscriptionStatusReserved      string = "Reserved"
+	SubscriptionStatusStale         string = "Stale"
+)
+
+// SubscriptionStatuses are the subscription statuses known to the operator.
+var SubscriptionStatuses = []string{
+	SubscriptionStatusActive,
+	SubscriptionStatusArchived,
+	SubscriptionStatusDeprovisioned,
+	SubscriptionStatusDisconnected,
+	SubscriptionStatusReserved,
+	SubscriptionStatusStale,
+}
+
+// IsKnownSubscriptionStatus returns true if the subscription status is one of the statuses known to the operator.
+func IsKnownSubscriptionStatus(status string) bool {
+	return slices.Contains(SubscriptionStatuses, status)
+}
+
 // Condition types for DiscoveredCluster
 const (
 	// ConditionAvailable indicates whether the cluster is active and sending telemetry
 	ConditionAvailable string = "Available"
 
+	// ConditionOCMAvailable mirrors the status reported by OCM when the Available condition uses the staleAfter threshold
+	ConditionOCMAvailable string = "OCMAvailable"
+
 	// ConditionManaged indicates whether the cluster has been imported as a ManagedCluster
 	ConditionManaged string = "Managed"
+
+	// ConditionExpiringSoon indicates whether the trial subscription of the cluster is about to end
+	ConditionExpiringSoon string = "ExpiringSoon"
+
+	// ConditionImported indicates the progress of importing the cluster as a ManagedCluster
+	ConditionImported string = "Imported"
+
+	// ConditionImportQueued indicates whether the import of the cluster waits in the import queue
+	ConditionImportQueued string = "ImportQueued"
+
+	// ConditionNameConflict indicates whether the display name of the cluster is already used by another ManagedCluster
+	ConditionNameConflict string = "NameConflict"
 )
 
 // Condition reasons for DiscoveredCluster
@@ -149,13 +239,102 @@ const (
 	// ReasonStaleTelemetry indicates the cluster has not sent telemetry recently (Stale)
 	ReasonStaleTelemetry string = "StaleTelemetry"
 
+	// ReasonDisconnected indicates the cluster has been disconnected from OCM (Disconnected)
+	ReasonDisconnected string = "Disconnected"
+
+	// ReasonProvisioning indicates the cluster subscription is reserved while the cluster is provisioned (Reserved)
+	ReasonProvisioning string = "Provisioning"
+
+	// ReasonDeprovisioned indicates the cluster has been deprovisioned (Deprovisioned)
+	ReasonDeprovisioned string = "Deprovisioned"
+
+	// ReasonArchived indicates the cluster has been archived in OCM (Archived)
+	ReasonArchived string = "Archived"
+
+	// ReasonNoTelemetry indicates the cluster has never reported telemetry, so its availability cannot be observed
+	ReasonNoTelemetry string = "NoTelemetry"
+
+	// ReasonUnknownStatus indicates OCM reported no status, or a status that is not recognized
+	ReasonUnknownStatus string = "UnknownStatus"
+
+	// ReasonImportPending indicates the ManagedCluster for the cluster has not been created yet
+	ReasonImportPending string = "ImportPending"
+
+	// ReasonResourcesCreated indicates the ManagedCluster has been created but not yet accepted by the hub
+	ReasonResourcesCreated string = "ResourcesCreated"
+
+	// ReasonJoining indicates the ManagedCluster is joining the hub but is not yet available
+	ReasonJoining string = "Joining"
+
+	// ReasonManagedClusterAvailable indicates the ManagedCluster has joined the hub and is available
+	ReasonManagedClusterAvailable string = "Available"
+
+	// ReasonImportFailed indicates the import of the ManagedCluster failed
+	ReasonImportFailed string = "ImportFailed"
+
+	// ReasonKlusterletConfigNotFound indicates the KlusterletConfig referenced for the import does not exist
+	ReasonKlusterletConfigNotFound string = "KlusterletConfigNotFound"
+
+	// ReasonManagedClusterAPINotServed indicates the hub does not serve the ManagedCluster API, so clusters cannot be
+	// imported
+	ReasonManagedClusterAPINotServed string = "ManagedClusterAPINotServed"
+
+	// ReasonAwaitingApproval indicates the import prerequisites exist and the Manual import waits for approval
+	ReasonAwaitingApproval string = "AwaitingApproval"
+
+	// ReasonActivityWithinThreshold indicates the last activity of the cluster is within the staleAfter threshold
+	ReasonActivityWithinThreshold string = "ActivityWithinThreshold"
+
+	// ReasonActivityThresholdExceeded indicates the last activity of the cluster is older than the staleAfter threshold
+	ReasonActivityThresholdExceeded string = "ActivityThresholdExceeded"
+
 	// ReasonImportedAsManagedCluster indicates the cluster has been imported
 	ReasonImportedAsManagedCluster string = "ImportedAsManagedCluster"
 
 	// ReasonNotImported indicates the cluster has not been imported
 	ReasonNotImported string = "NotImported"
+
+	// ReasonTrialActive indicates the trial subscription does not end within the expiry window
+	ReasonTrialActive string = "TrialActive"
+
+	// ReasonTrialEndingSoon indicates the trial subscription ends within the expiry window
+	ReasonTrialEndingSoon string = "TrialEndingSoon"
+
+	// ReasonTrialExpired indicates the trial subscription has ended
+	ReasonTrialExpired string = "TrialExpired"
+
+	// ReasonImportQueued indicates the import waits for a free slot in the import queue
+	ReasonImportQueued string = "Queued"
+
+	// ReasonImportDequeued indicates the import does not wait in the import queue
+	ReasonImportDequeued string = "Dequeued"
+
+	// ReasonNameAvailable indicates the ManagedCluster is named after the display name of the cluster
+	ReasonNameAvailable string = "NameAvailable"
+
+	// ReasonNameSuffixed indicates the display name is taken and the ManagedCluster name is suffixed with the cluster ID
+	ReasonNameSuffixed string = "NameSuffixed"
+
+	// ReasonNameUnavailable indicates no unique ManagedCluster name is available for the cluster
+	ReasonNameUnavailable string = "NameUnavailable"
 )
 
+// MaxReimportHistory is the number of reimports recorded in the status of a DiscoveredCluster.
+const MaxReimportHistory = 10
+
+// ReimportRecord records a reimport of a DiscoveredCluster.
+type ReimportRecord struct {
+	// RequestedAt is the time of the reimport request, as set in the reimport annotation.
+	RequestedAt metav1.Time `json:"requestedAt"`
+
+	// StartedAt is the time the import was restarted.
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// ManagedClusterName is the name of the deleted ManagedCluster that the cluster is reimported as.
+	// +optional
+	ManagedClusterName string `json:"managedClusterName,omitempty"`
+}
+
 // DiscoveredClusterStatus defines the observed state of DiscoveredCluster
 type DiscoveredClusterStatus struct {
 	// Conditions represent the latest available observations of the DiscoveredCluster's state
@@ -165,6 +344,91 @@ type DiscoveredClusterStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []DiscoveredClusterCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ManagedClusterName is the name of the ManagedCluster and of the namespace used to import the cluster
+	// +optional
+	ManagedClusterName string `json:"managedClusterName,omitempty"`
+
+	// ImportStrategy is the effective import strategy of the DiscoveredCluster (Automatic, Manual or Disabled)
+	// +optional
+	ImportStrategy string `json:"importStrategy,omitempty"`
+
+	// ImportQueuePosition is the position of the cluster in the import queue while its import waits to start
+	// +optional
+	ImportQueuePosition int32 `json:"importQueuePosition,omitempty"`
+
+	// ReimportHistory records the most recent reimports of the cluster, oldest first
+	// +optional
+	ReimportHistory []ReimportRecord `json:"reimportHistory,omitempty"`
+}
+
+/*
+EffectiveImportStrategy returns the import strategy of the DiscoveredCluster. A strategy set on the DiscoveredCluster
+takes precedence over a strategy set on the DiscoveryConfig, and clusters are imported automatically when neither sets
+a valid strategy. The DiscoveryConfig may be nil.
+*/
+func EffectiveImportStrategy(dc *DiscoveredCluster, config *DiscoveryConfig) string {
+	if strategy := dc.GetAnnotations()[ImportStrategyAnnotation]; IsValidImportStrategy(strategy) {
+		return strategy
+	}
+
+	if config != nil {
+		if strategy := config.GetAnnotations()[ImportStrategyAnnotation]; IsValidImportStrategy(strategy) {
+			return strategy
+		}
+	}
+	return ImportStrategyAutomatic
+}
+
+/*
+IsManagedClusterNameSuffixEnabled returns whether the ManagedCluster name of the DiscoveredCluster may be suffixed with
+its cluster ID when the name is already used. A value set on the DiscoveredCluster takes precedence over a value set on
+the DiscoveryConfig, and suffixing is enabled when neither sets a valid value. The DiscoveryConfig may be nil.
+*/
+func IsManagedClusterNameSuffixEnabled(dc *DiscoveredCluster, config *DiscoveryConfig) bool {
+	if enabled, err := strconv.ParseBool(dc.GetAnnotations()[ManagedClusterNameSuffixAnnotation]); err == nil {
+		return enabled
+	}
+
+	if config != nil {
+		if enabled, err := strconv.ParseBool(config.GetAnnotations()[ManagedClusterNameSuffixAnnotation]); err == nil {
+			return enabled
+		}
+	}
+	return true
+}
+
+/*
+GetManagedClusterName returns the name of the ManagedCluster and of the namespace used to import the DiscoveredCluster.
+It defaults to the preferred ManagedCluster name until a name is recorded in the status.
+*/
+func GetManagedClusterName(dc *DiscoveredCluster) string {
+	if dc.Status.ManagedClusterName != "" {
+		return dc.Status.ManagedClusterName
+	}
+	return GetPreferredManagedClusterName(dc)
+}
+
+/*
+GetPreferredManagedClusterName returns the display name of the DiscoveredCluster converted into an RFC 1123 label, or
+its name converted the same way if the display name has no valid characters. The display name itself is synced from
+OCM and left unchanged.
+*/
+func GetPreferredManagedClusterName(dc *DiscoveredCluster) string {
+	if name := NormalizeName(dc.Spec.DisplayName, MaxManagedClusterNameLength); name != "" {
+		return name
+	}
+	return NormalizeName(dc.Spec.Name, MaxManagedClusterNameLength)
+}
+
+// IsValidImportStrategy returns true if the strategy is Automatic, Manual or Disabled
+func IsValidImportStrategy(strategy string) bool {
+	switch strategy {
+	case ImportStrategyAutomatic, ImportStrategyManual, ImportStrategyDisabled:
+		return true
+	default:
+		return false
+	}
 }
 
 //+kubebuilder:object:root=true
@@ -175,6 +439,8 @@ type DiscoveredClusterStatus struct {
 // +kubebuilder:printcolumn:name="Cloud Provider",type="string",JSONPath=".spec.cloudProvider",description="Cloud provider where the cluster is hosted (e.g., AWS, Azure, GCP)"
 // +kubebuilder:printcolumn:name="Region",type="string",JSONPath=".spec.region",description="Region where the cluster is deployed (e.g., us-east-1, us-central1)"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".spec.status",description="Current state of the discovered cluster (e.g Active, Stale)"
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status",description="Whether the cluster is available"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].reason",description="Reason for the availability of the cluster (e.g RecentTelemetry, Disconnected, Provisioning)",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // DiscoveredCluster is the Schema for the discoveredclusters API
 type DiscoveredCluster struct {
@@ -211,6 +477,15 @@ func timestampsEqual(a, b *metav1.Time) bool {
 
 // Equal reports whether the spec of a is equal to b.
 func (a DiscoveredCluster) Equal(b DiscoveredCluster) bool {
+	return a.SourceEqual(b) &&
+		a.Spec.ImportAsManagedCluster == b.Spec.ImportAsManagedCluster &&
+		a.Spec.IsManagedCluster == b.Spec.IsManagedCluster
+}
+
+// SourceEqual reports whether the OCM-sourced spec fields of a are equal to b. Fields that record user intent
+// (ImportAsManagedCluster) or the managed state of the cluster (IsManagedCluster) are owned by other field managers
+// and are not compared.
+func (a DiscoveredCluster) SourceEqual(b DiscoveredCluster) bool {
 	if a.Spec.APIURL != b.Spec.APIURL ||
 		!timestampsEqual(a.Spec.ActivityTimestamp, b.Spec.ActivityTimestamp) ||
 		a.Spec.CloudProvider != b.Spec.CloudProvider ||
@@ -218,14 +493,15 @@ func (a DiscoveredCluster) Equal(b DiscoveredCluster) bool {
 		!timestampsEqual(a.Spec.CreationTimestamp, b.Spec.CreationTimestamp) ||
 		a.Spec.Credential != b.Spec.Credential ||
 		a.Spec.DisplayName != b.Spec.DisplayName ||
-		a.Spec.ImportAsManagedCluster != b.Spec.ImportAsManagedCluster ||
-		a.Spec.IsManagedCluster != b.Spec.IsManagedCluster ||
 		a.Spec.Name != b.Spec.Name ||
+		a.Spec.OCPClusterID != b.Spec.OCPClusterID ||
 		a.Spec.OpenshiftVersion != b.Spec.OpenshiftVersion ||
 		a.Spec.Provenance != b.Spec.Provenance ||
 		a.Spec.Region != b.Spec.Region ||
+		a.Spec.RHOCMClusterID != b.Spec.RHOCMClusterID ||
 		a.Spec.SupportLevel != b.Spec.SupportLevel ||
 		a.Spec.Status != b.Spec.Status ||
+		!timestampsEqual(a.Spec.TrialEndDate, b.Spec.TrialEndDate) ||
 		a.Spec.Type != b.Spec.Type ||
 		a.Spec.Usage != b.Spec.Usage {
 		return false
@@ -136,3 +136,134 @@ func TestEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceEqual(t *testing.T) {
+	time1 := metav1.NewTime(time.Date(2022, 5, 22, 0, 0, 0, 0, time.UTC))
+
+	base := DiscoveredCluster{
+		Spec: DiscoveredClusterSpec{
+			Name:              "managedcluster",
+			DisplayName:       "managedcluster",
+			OCPClusterID:      "testID",
+			RHOCMClusterID:    "testID",
+			APIURL:            "testURL",
+			ActivityTimestamp: &time1,
+			Type:              "test",
+			Status:            "Active",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(dc *DiscoveredCluster)
+		want   bool
+	}{
+		{
+			name:   "ignores user intent",
+			modify: func(dc *DiscoveredCluster) { dc.Spec.ImportAsManagedCluster = true },
+			want:   true,
+		},
+		{
+			name:   "ignores managed state",
+			modify: func(dc *DiscoveredCluster) { dc.Spec.IsManagedCluster = true },
+			want:   true,
+		},
+		{
+			name:   "detects OCM cluster ID change",
+			modify: func(dc *DiscoveredCluster) { dc.Spec.RHOCMClusterID = "otherID" },
+			want:   false,
+		},
+		{
+			name:   "detects status change",
+			modify: func(dc *DiscoveredCluster) { dc.Spec.Status = "Stale" },
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := *base.DeepCopy()
+			tt.modify(&other)
+			if got := base.SourceEqual(other); got != tt.want {
+				t.Errorf("SourceEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetManagedClusterName(t *testing.T) {
+	tests := []struct {
+		name string
+		dc   DiscoveredCluster
+		want string
+	}{
+		{
+			name: "uses the display name converted into a valid name",
+			dc:   DiscoveredCluster{Spec: DiscoveredClusterSpec{DisplayName: "My_Cluster", Name: "abc"}},
+			want: "my-cluster",
+		},
+		{
+			name: "uses the name when the display name has no valid characters",
+			dc:   DiscoveredCluster{Spec: DiscoveredClusterSpec{DisplayName: "__", Name: "ABC"}},
+			want: "abc",
+		},
+		{
+			name: "uses the name recorded in the status",
+			dc: DiscoveredCluster{
+				Spec:   DiscoveredClusterSpec{DisplayName: "My_Cluster"},
+				Status: DiscoveredClusterStatus{ManagedClusterName: "my-cluster-abc"},
+			},
+			want: "my-cluster-abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetManagedClusterName(&tt.dc); got != tt.want {
+				t.Errorf("GetManagedClusterName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveImportStrategy(t *testing.T) {
+	withStrategy := func(strategy string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Annotations: map[string]string{ImportStrategyAnnotation: strategy}}
+	}
+
+	tests := []struct {
+		name   string
+		dc     *DiscoveredCluster
+		config *DiscoveryConfig
+		want   string
+	}{
+		{
+			name: "Defaults to Automatic",
+			dc:   &DiscoveredCluster{},
+			want: ImportStrategyAutomatic,
+		},
+		{
+			name:   "Inherits strategy of the DiscoveryConfig",
+			dc:     &DiscoveredCluster{},
+			config: &DiscoveryConfig{ObjectMeta: withStrategy(ImportStrategyManual)},
+			want:   ImportStrategyManual,
+		},
+		{
+			name:   "Strategy of the DiscoveredCluster takes precedence",
+			dc:     &DiscoveredCluster{ObjectMeta: withStrategy(ImportStrategyDisabled)},
+			config: &DiscoveryConfig{ObjectMeta: withStrategy(ImportStrategyManual)},
+			want:   ImportStrategyDisabled,
+		},
+		{
+			name:   "Invalid strategy is ignored",
+			dc:     &DiscoveredCluster{ObjectMeta: withStrategy("Sometimes")},
+			config: &DiscoveryConfig{ObjectMeta: withStrategy(ImportStrategyManual)},
+			want:   ImportStrategyManual,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveImportStrategy(tt.dc, tt.config); got != tt.want {
+				t.Errorf("EffectiveImportStrategy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
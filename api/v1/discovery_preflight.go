@@ -0,0 +1,160 @@
+// Copyright Contributors to the Open Cluster Management project
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stolostron/discovery/pkg/ocm/auth"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+/*
+preflightImport checks that the import of the DiscoveredCluster can succeed when the import is requested, so that
+import failures are reported at edit time instead of by the DiscoveredCluster controller. Problems that prevent the
+import are returned as an error. Problems that the controller recovers from, or that may be resolved later, are
+returned as warnings. Checks that need the cluster are skipped when the webhook has no client.
+*/
+func (r *DiscoveredCluster) preflightImport() (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var errs []error
+
+	switch r.Spec.Status {
+	case "", SubscriptionStatusActive:
+	case SubscriptionStatusArchived, SubscriptionStatusDeprovisioned:
+		errs = append(errs, fmt.Errorf("the cluster is %s", r.Spec.Status))
+	default:
+		warnings = append(warnings, fmt.Sprintf("the cluster is %s, so its import may not complete until it is %s",
+			r.Spec.Status, SubscriptionStatusActive))
+	}
+
+	if Client == nil {
+		return warnings, utilerrors.NewAggregate(errs)
+	}
+
+	checks := []func() (string, error){r.preflightManagedClusterName}
+	switch {
+	case r.Spec.Type == "MultiClusterEngineHCP":
+	case IsCredentialImportClusterType(r.Spec.Type):
+		checks = append(checks, r.preflightImportCredential)
+	default:
+		checks = append(checks, r.preflightOCMCredential)
+	}
+
+	for _, check := range checks {
+		warning, err := check()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, utilerrors.NewAggregate(errs)
+}
+
+/*
+preflightManagedClusterName checks whether a ManagedCluster of another cluster already uses the name of the
+ManagedCluster of the DiscoveredCluster. The ManagedCluster of a MultiClusterEngineHCP cluster must be named after the
+hosted cluster, so the conflict prevents its import. Other clusters are imported with a name suffixed with their cluster
+ID instead.
+*/
+func (r *DiscoveredCluster) preflightManagedClusterName() (string, error) {
+	name := GetManagedClusterName(r)
+	mc := &clusterapiv1.ManagedCluster{}
+	if err := Client.Get(context.TODO(), types.NamespacedName{Name: name}, mc); apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return fmt.Sprintf("ManagedCluster %s could not be checked for name conflicts: %v", name, err), nil
+	}
+
+	clusterID := mc.GetLabels()["clusterID"]
+	if clusterID == "" || clusterID == r.Spec.Name {
+		return "", nil
+	}
+
+	if r.Spec.Type == "MultiClusterEngineHCP" {
+		return "", fmt.Errorf("ManagedCluster %s already exists for the cluster with ID %s", name, clusterID)
+	}
+	return fmt.Sprintf("ManagedCluster %s already exists for the cluster with ID %s, so the cluster is imported "+
+		"with a name suffixed with its cluster ID", name, clusterID), nil
+}
+
+// preflightOCMCredential checks that the OCM credential Secret of the DiscoveredCluster exists and uses a supported
+// auth_method, since it is used to build the auto-import Secret.
+func (r *DiscoveredCluster) preflightOCMCredential() (string, error) {
+	nn := types.NamespacedName{Name: r.Spec.Credential.Name, Namespace: r.Spec.Credential.Namespace}
+	if nn.Name == "" {
+		return "", fmt.Errorf("the cluster has no credential Secret")
+	}
+
+	secret := &corev1.Secret{}
+	if err := Client.Get(context.TODO(), nn, secret); apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("credential Secret %s/%s does not exist", nn.Namespace, nn.Name)
+	} else if err != nil {
+		return fmt.Sprintf("credential Secret %s/%s could not be verified: %v", nn.Namespace, nn.Name, err), nil
+	}
+
+	if _, err := auth.ParseSecretForAuth(secret); err != nil {
+		return "", fmt.Errorf("invalid credential Secret: %w", err)
+	}
+	return "", nil
+}
+
+/*
+preflightImportCredential checks the import credential of a self-managed cluster, referenced by the DiscoveredCluster or
+mapped to it in the DiscoveryConfig. A credential that is not configured or does not exist yet only causes a warning,
+since the import waits for it.
+*/
+func (r *DiscoveredCluster) preflightImportCredential() (string, error) {
+	name := ""
+	if r.Spec.ImportCredential != nil {
+		name = r.Spec.ImportCredential.Name
+	}
+	if config := getDiscoveryConfig(r.Namespace); name == "" && config != nil {
+		for _, m := range config.Spec.ImportCredentials {
+			if m.ClusterName == r.Spec.DisplayName {
+				name = m.SecretName
+				break
+			}
+		}
+	}
+	if name == "" {
+		return "no import credential is configured, so the import waits until one is set", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: r.Namespace}, secret); err != nil {
+		return fmt.Sprintf("import credential Secret %s could not be read, so the import may wait for it: %v",
+			name, err), nil
+	}
+
+	_, kubeconfigOk := secret.Data["kubeconfig"]
+	_, tokenOk := secret.Data["token"]
+	_, serverOk := secret.Data["server"]
+	if !kubeconfigOk && !(tokenOk && serverOk) {
+		return "", fmt.Errorf("import credential Secret %s must contain kubeconfig, or token and server", name)
+	}
+	return "", nil
+}
@@ -0,0 +1,172 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiscoveredCluster_preflightImport(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocm-token", Namespace: "bar"},
+			Data:       map[string][]byte{"ocmAPIToken": []byte("token")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bad-token", Namespace: "bar"},
+			Data:       map[string][]byte{"auth_method": []byte("password")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig", Namespace: "bar"},
+			Data:       map[string][]byte{"kubeconfig": []byte("apiVersion: v1")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "bar"},
+		},
+		&clusterapiv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "taken", Labels: map[string]string{"clusterID": "other-id"}},
+		},
+		&clusterapiv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "mine", Labels: map[string]string{"clusterID": "cluster-id"}},
+		},
+	}
+
+	newCluster := func(mutate func(*DiscoveredCluster)) *DiscoveredCluster {
+		dc := &DiscoveredCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+			Spec: DiscoveredClusterSpec{
+				Name:                   "cluster-id",
+				DisplayName:            "foo",
+				Type:                   "ROSA",
+				Status:                 "Active",
+				Credential:             corev1.ObjectReference{Name: "ocm-token", Namespace: "bar"},
+				ImportAsManagedCluster: true,
+			},
+		}
+		if mutate != nil {
+			mutate(dc)
+		}
+		return dc
+	}
+
+	tests := []struct {
+		name        string
+		dc          *DiscoveredCluster
+		wantErr     string
+		wantWarning string
+	}{
+		{
+			name: "Passes for an active ROSA cluster",
+			dc:   newCluster(nil),
+		},
+		{
+			name:    "Rejects deprovisioned clusters",
+			dc:      newCluster(func(dc *DiscoveredCluster) { dc.Spec.Status = "Deprovisioned" }),
+			wantErr: "the cluster is Deprovisioned",
+		},
+		{
+			name:        "Warns about stale clusters",
+			dc:          newCluster(func(dc *DiscoveredCluster) { dc.Spec.Status = "Stale" }),
+			wantWarning: "the cluster is Stale",
+		},
+		{
+			name:    "Rejects a missing OCM credential Secret",
+			dc:      newCluster(func(dc *DiscoveredCluster) { dc.Spec.Credential.Name = "missing" }),
+			wantErr: "credential Secret bar/missing does not exist",
+		},
+		{
+			name:    "Rejects an unsupported auth_method",
+			dc:      newCluster(func(dc *DiscoveredCluster) { dc.Spec.Credential.Name = "bad-token" }),
+			wantErr: "unsupported auth_method",
+		},
+		{
+			name:        "Warns about a ManagedCluster name used by another cluster",
+			dc:          newCluster(func(dc *DiscoveredCluster) { dc.Spec.DisplayName = "taken" }),
+			wantWarning: "ManagedCluster taken already exists for the cluster with ID other-id",
+		},
+		{
+			name: "Rejects a hosted cluster name used by another cluster",
+			dc: newCluster(func(dc *DiscoveredCluster) {
+				dc.Spec.DisplayName = "taken"
+				dc.Spec.Type = "MultiClusterEngineHCP"
+			}),
+			wantErr: "ManagedCluster taken already exists for the cluster with ID other-id",
+		},
+		{
+			name: "Passes for the ManagedCluster of the same cluster",
+			dc:   newCluster(func(dc *DiscoveredCluster) { dc.Spec.DisplayName = "mine" }),
+		},
+		{
+			name: "Passes for a self-managed cluster with a kubeconfig",
+			dc: newCluster(func(dc *DiscoveredCluster) {
+				dc.Spec.Type = "OCP"
+				dc.Spec.ImportCredential = &corev1.LocalObjectReference{Name: "kubeconfig"}
+			}),
+		},
+		{
+			name:        "Warns about a self-managed cluster without import credential",
+			dc:          newCluster(func(dc *DiscoveredCluster) { dc.Spec.Type = "OCP" }),
+			wantWarning: "no import credential is configured",
+		},
+		{
+			name: "Rejects an import credential without kubeconfig or token",
+			dc: newCluster(func(dc *DiscoveredCluster) {
+				dc.Spec.Type = "OCP"
+				dc.Spec.ImportCredential = &corev1.LocalObjectReference{Name: "empty"}
+			}),
+			wantErr: "must contain kubeconfig, or token and server",
+		},
+	}
+
+	s := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme, clusterapiv1.Install, AddToScheme,
+	} {
+		if err := add(s); err != nil {
+			t.Fatalf("failed to register scheme: %v", err)
+		}
+	}
+	Client = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+	defer func() { Client = nil }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := tt.dc.preflightImport()
+
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("preflightImport() error = %v, want no error", err)
+			} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("preflightImport() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if got := strings.Join(warnings, "\n"); tt.wantWarning == "" && got != "" {
+				t.Errorf("preflightImport() warnings = %q, want none", got)
+			} else if !strings.Contains(got, tt.wantWarning) {
+				t.Errorf("preflightImport() warnings = %q, want %q", got, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -18,12 +18,20 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"reflect"
 	"regexp"
+	"slices"
+	"strings"
 
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	cl "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -37,12 +45,24 @@ var (
 	Client               cl.Client
 )
 
-// ValidatingWebhook returns the ValidatingWebhookConfiguration used for the discoveredcluster
+/*
+userIntentFields are the spec fields of a DiscoveredCluster, by JSON name, that users can edit. The other fields are
+synced from OCM and can only be changed by the discovery operator.
+*/
+var userIntentFields = []string{"importAsManagedCluster", "importApproved", "importCredential", "managedCluster"}
+
+// MaxManagedClusterNameLength is the maximum length of a ManagedCluster name, which is also used as a namespace.
+const MaxManagedClusterNameLength = 63
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ValidatingWebhook returns the ValidatingWebhookConfiguration used for the discoveredcluster and the discoveryconfig
 // linked to a service in the provided namespace
 func ValidatingWebhook(namespace string) *admissionregistration.ValidatingWebhookConfiguration {
 	fail := admissionregistration.Fail
 	none := admissionregistration.SideEffectClassNone
 	path := "/validate-discovery-open-cluster-management-io-v1-discoveredcluster"
+	configPath := "/validate-discovery-open-cluster-management-io-v1-discoveryconfig"
 	return &admissionregistration.ValidatingWebhookConfiguration{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "admissionregistration.k8s.io/v1",
@@ -83,6 +103,84 @@ func ValidatingWebhook(namespace string) *admissionregistration.ValidatingWebhoo
 				},
 				SideEffects: &none,
 			},
+			{
+				AdmissionReviewVersions: []string{
+					"v1",
+					"v1beta1",
+				},
+				Name: "discoveryconfig.discovery.open-cluster-management.io",
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{
+						Name:      "discovery-operator-webhook-service",
+						Namespace: namespace,
+						Path:      &configPath,
+					},
+				},
+				FailurePolicy: &fail,
+				Rules: []admissionregistration.RuleWithOperations{
+					{
+						Rule: admissionregistration.Rule{
+							APIGroups:   []string{GroupVersion.Group},
+							APIVersions: []string{GroupVersion.Version},
+							Resources:   []string{"discoveryconfigs"},
+						},
+						Operations: []admissionregistration.OperationType{
+							admissionregistration.Create,
+							admissionregistration.Update,
+						},
+					},
+				},
+				SideEffects: &none,
+			},
+		},
+	}
+}
+
+// MutatingWebhook returns the MutatingWebhookConfiguration used to default the discoveredcluster
+// linked to a service in the provided namespace
+func MutatingWebhook(namespace string) *admissionregistration.MutatingWebhookConfiguration {
+	fail := admissionregistration.Fail
+	none := admissionregistration.SideEffectClassNone
+	path := "/mutate-discovery-open-cluster-management-io-v1-discoveredcluster"
+	return &admissionregistration.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "admissionregistration.k8s.io/v1",
+			Kind:       "MutatingWebhookConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "discovery.open-cluster-management.io",
+			Annotations: map[string]string{"service.beta.openshift.io/inject-cabundle": "true"},
+		},
+		Webhooks: []admissionregistration.MutatingWebhook{
+			{
+				AdmissionReviewVersions: []string{
+					"v1",
+					"v1beta1",
+				},
+				Name: "mutate.discovery.open-cluster-management.io",
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{
+						Name:      "discovery-operator-webhook-service",
+						Namespace: namespace,
+						Path:      &path,
+					},
+				},
+				FailurePolicy: &fail,
+				Rules: []admissionregistration.RuleWithOperations{
+					{
+						Rule: admissionregistration.Rule{
+							APIGroups:   []string{GroupVersion.Group},
+							APIVersions: []string{GroupVersion.Version},
+							Resources:   []string{"discoveredclusters"},
+						},
+						Operations: []admissionregistration.OperationType{
+							admissionregistration.Create,
+							admissionregistration.Update,
+						},
+					},
+				},
+				SideEffects: &none,
+			},
 		},
 	}
 }
@@ -91,14 +189,67 @@ func (r *DiscoveredCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	Client = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&discoveredClusterValidator{}).
 		Complete()
 }
 
 var _ webhook.Defaulter = &DiscoveredCluster{}
 
-// Default implements webhook.Defaulter so a webhook will be registered for the type
+/*
+Default implements webhook.Defaulter so a webhook will be registered for the type. DiscoveredClusters that are imported
+get the import defaults of their namespace. Defaults only fill fields that are not set, so they are recorded on the
+object when the import is requested instead of being derived again on every reconcile.
+*/
 func (r *DiscoveredCluster) Default() {
 	discoveredclusterLog.Info("default", "Name", r.Name)
+
+	if !r.Spec.ImportAsManagedCluster {
+		return
+	}
+
+	applyImportDefaults(r, getDiscoveryConfig(r.Namespace), getImportPolicy(r))
+}
+
+/*
+applyImportDefaults sets the import strategy of the DiscoveryConfig, and the ManagedClusterSet and KlusterletConfig of
+the DiscoveryConfig and of the DiscoveryImportPolicy that selected the DiscoveredCluster, on the DiscoveredCluster when
+it does not set them. The policy overrides the DiscoveryConfig. The DiscoveryConfig and the policy may be nil.
+*/
+func applyImportDefaults(r *DiscoveredCluster, config *DiscoveryConfig, policy *DiscoveryImportPolicy) {
+	defaults := ManagedClusterSettings{}
+
+	if config != nil {
+		if strategy := config.GetAnnotations()[ImportStrategyAnnotation]; IsValidImportStrategy(strategy) &&
+			!IsValidImportStrategy(r.GetAnnotations()[ImportStrategyAnnotation]) {
+			if r.Annotations == nil {
+				r.Annotations = map[string]string{}
+			}
+			r.Annotations[ImportStrategyAnnotation] = strategy
+		}
+
+		if config.Spec.ManagedCluster != nil {
+			defaults.ClusterSet = config.Spec.ManagedCluster.ClusterSet
+			defaults.KlusterletConfig = config.Spec.ManagedCluster.KlusterletConfig
+		}
+	}
+
+	if policy != nil && policy.Spec.ManagedClusterSet != "" {
+		defaults.ClusterSet = policy.Spec.ManagedClusterSet
+	}
+
+	if defaults.ClusterSet == "" && defaults.KlusterletConfig == "" {
+		return
+	}
+
+	if r.Spec.ManagedCluster == nil {
+		r.Spec.ManagedCluster = &ManagedClusterSettings{}
+	}
+	if r.Spec.ManagedCluster.ClusterSet == "" {
+		r.Spec.ManagedCluster.ClusterSet = defaults.ClusterSet
+	}
+	if r.Spec.ManagedCluster.KlusterletConfig == "" {
+		r.Spec.ManagedCluster.KlusterletConfig = defaults.KlusterletConfig
+	}
 }
 
 var _ webhook.Validator = &DiscoveredCluster{}
@@ -111,22 +262,43 @@ func (r *DiscoveredCluster) ValidateCreate() (admission.Warnings, error) {
 	if !IsSupportedClusterType(r.Spec.Type) && r.Spec.ImportAsManagedCluster {
 		err := fmt.Errorf(
 			"cannot create DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters of type '%s'. "+
-				"Only ROSA type clusters support auto import", r.Name, r.Spec.Type)
+				"Only ROSA, MultiClusterEngineHCP, OCP, OSD and ARO type clusters support auto import",
+			r.Name, r.Spec.Type)
 
 		discoveredclusterLog.Error(err, "validation failed")
 		return nil, err
 	}
 
-	if !IsStringValid(r.Spec.DisplayName) && r.Spec.ImportAsManagedCluster {
+	if GetPreferredManagedClusterName(r) == "" && r.Spec.ImportAsManagedCluster {
 		err := fmt.Errorf(
-			"cannot update DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters with an invalid display name '%s'. "+
-				"Display name must consist of lowercase alphanumeric characters or '-'", r.Name, r.Spec.DisplayName)
+			"cannot create DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters whose display name "+
+				"'%s' and name contain no lowercase alphanumeric characters to build a ManagedCluster name from",
+			r.Name, r.Spec.DisplayName)
 
 		discoveredclusterLog.Error(err, "validation failed")
 		return nil, err
 	}
 
-	return nil, nil
+	if r.Spec.ImportAsManagedCluster && isImportDisabled(r) {
+		err := fmt.Errorf(
+			"cannot create DiscoveredCluster '%s': importAsManagedCluster is not allowed when the import strategy is '%s'",
+			r.Name, ImportStrategyDisabled)
+
+		discoveredclusterLog.Error(err, "validation failed")
+		return nil, err
+	}
+
+	warnings := getStatusWarnings(r.Spec.Status)
+	if r.Spec.ImportAsManagedCluster {
+		preflightWarnings, err := r.preflightImport()
+		if err != nil {
+			err = fmt.Errorf("cannot create DiscoveredCluster '%s': import pre-flight checks failed: %w", r.Name, err)
+			discoveredclusterLog.Error(err, "validation failed")
+		}
+		return append(warnings, preflightWarnings...), err
+	}
+
+	return warnings, nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -138,22 +310,49 @@ func (r *DiscoveredCluster) ValidateUpdate(old runtime.Object) (admission.Warnin
 	if !IsSupportedClusterType(oldDiscoveredCluster.Spec.Type) && r.Spec.ImportAsManagedCluster {
 		err := fmt.Errorf(
 			"cannot update DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters of type '%s'. "+
-				"Only ROSA type clusters support auto import", r.Name, r.Spec.Type)
+				"Only ROSA, MultiClusterEngineHCP, OCP, OSD and ARO type clusters support auto import",
+			r.Name, r.Spec.Type)
 
 		discoveredclusterLog.Error(err, "validation failed")
 		return nil, err
 	}
 
-	if !IsStringValid(r.Spec.DisplayName) && r.Spec.ImportAsManagedCluster {
+	if GetPreferredManagedClusterName(r) == "" && r.Spec.ImportAsManagedCluster {
 		err := fmt.Errorf(
-			"cannot update DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters with an invalid display name '%s'. "+
-				"Display name must consist of lowercase alphanumeric characters or '-'", r.Name, r.Spec.DisplayName)
+			"cannot update DiscoveredCluster '%s': importAsManagedCluster is not allowed for clusters whose display name "+
+				"'%s' and name contain no lowercase alphanumeric characters to build a ManagedCluster name from",
+			r.Name, r.Spec.DisplayName)
 
 		discoveredclusterLog.Error(err, "validation failed")
 		return nil, err
 	}
 
-	return nil, nil
+	// Only reject setting importAsManagedCluster, so that clusters that were already importing can still be updated.
+	if r.Spec.ImportAsManagedCluster && !oldDiscoveredCluster.Spec.ImportAsManagedCluster && isImportDisabled(r) {
+		err := fmt.Errorf(
+			"cannot update DiscoveredCluster '%s': importAsManagedCluster is not allowed when the import strategy is '%s'",
+			r.Name, ImportStrategyDisabled)
+
+		discoveredclusterLog.Error(err, "validation failed")
+		return nil, err
+	}
+
+	var warnings admission.Warnings
+	if r.Spec.Status != oldDiscoveredCluster.Spec.Status {
+		warnings = getStatusWarnings(r.Spec.Status)
+	}
+
+	// Only check the import when it is requested, so that clusters that were already importing can still be updated.
+	if r.Spec.ImportAsManagedCluster && !oldDiscoveredCluster.Spec.ImportAsManagedCluster {
+		preflightWarnings, err := r.preflightImport()
+		if err != nil {
+			err = fmt.Errorf("cannot update DiscoveredCluster '%s': import pre-flight checks failed: %w", r.Name, err)
+			discoveredclusterLog.Error(err, "validation failed")
+		}
+		return append(warnings, preflightWarnings...), err
+	}
+
+	return warnings, nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -162,6 +361,129 @@ func (r *DiscoveredCluster) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
+/*
+discoveredClusterValidator validates DiscoveredClusters with the webhook.Validator implementation of DiscoveredCluster,
+and additionally rejects updates of the fields synced from OCM by anyone but the discovery operator, which requires the
+user from the admission request.
+*/
+type discoveredClusterValidator struct{}
+
+var _ webhook.CustomValidator = &discoveredClusterValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *discoveredClusterValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return obj.(*DiscoveredCluster).ValidateCreate()
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *discoveredClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (
+	admission.Warnings, error) {
+	r, old := newObj.(*DiscoveredCluster), oldObj.(*DiscoveredCluster)
+
+	if req, err := admission.RequestFromContext(ctx); err == nil && !isOperatorUser(req.UserInfo.Username) {
+		if field := getChangedSourceField(old, r); field != "" {
+			err := fmt.Errorf(
+				"cannot update DiscoveredCluster '%s': spec.%s is synced from OCM and can only be changed by the "+
+					"discovery operator. Only %s can be changed", r.Name, field, strings.Join(userIntentFields, ", "))
+
+			discoveredclusterLog.Error(err, "validation failed", "User", req.UserInfo.Username)
+			return nil, err
+		}
+	}
+
+	return r.ValidateUpdate(old)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *discoveredClusterValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return obj.(*DiscoveredCluster).ValidateDelete()
+}
+
+/*
+getStatusWarnings returns a warning when the subscription status is not one of the statuses known to the operator. The
+status is not rejected, since OCM may report new statuses before the operator knows them.
+*/
+func getStatusWarnings(status string) admission.Warnings {
+	if status == "" || IsKnownSubscriptionStatus(status) {
+		return nil
+	}
+	return admission.Warnings{fmt.Sprintf("unknown subscription status '%s', it is expected to be one of %s", status,
+		strings.Join(SubscriptionStatuses, ", "))}
+}
+
+/*
+isOperatorUser returns true if the user is the service account of the discovery operator. The namespace and the name
+of the service account are injected into the operator by the downward API.
+*/
+func isOperatorUser(username string) bool {
+	namespace, serviceAccount := os.Getenv("POD_NAMESPACE"), os.Getenv("POD_SERVICE_ACCOUNT")
+	return namespace != "" && serviceAccount != "" &&
+		username == fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount)
+}
+
+// getChangedSourceField returns the JSON name of the first spec field synced from OCM that differs between the old and
+// the new DiscoveredCluster, or an empty string if only user intent fields changed.
+func getChangedSourceField(old, r *DiscoveredCluster) string {
+	oldSpec, newSpec := reflect.ValueOf(old.Spec), reflect.ValueOf(r.Spec)
+	for i := 0; i < newSpec.NumField(); i++ {
+		field := strings.Split(newSpec.Type().Field(i).Tag.Get("json"), ",")[0]
+		if slices.Contains(userIntentFields, field) {
+			continue
+		}
+
+		if !equality.Semantic.DeepEqual(oldSpec.Field(i).Interface(), newSpec.Field(i).Interface()) {
+			return field
+		}
+	}
+	return ""
+}
+
+/*
+isImportDisabled returns true if the effective import strategy of the DiscoveredCluster is Disabled. The strategy of the
+DiscoveryConfig in the namespace of the DiscoveredCluster is taken into account when it can be read.
+*/
+func isImportDisabled(r *DiscoveredCluster) bool {
+	return EffectiveImportStrategy(r, getDiscoveryConfig(r.Namespace)) == ImportStrategyDisabled
+}
+
+// getDiscoveryConfig returns the DiscoveryConfig in the namespace, or nil if it cannot be read.
+func getDiscoveryConfig(namespace string) *DiscoveryConfig {
+	if Client == nil {
+		return nil
+	}
+
+	config := &DiscoveryConfig{}
+	nn := types.NamespacedName{Name: DefaultDiscoveryConfigName, Namespace: namespace}
+	if err := Client.Get(context.TODO(), nn, config); err != nil {
+		return nil
+	}
+	return config
+}
+
+// getImportPolicy returns the DiscoveryImportPolicy that selected the DiscoveredCluster, or nil if there is none or it
+// cannot be read.
+func getImportPolicy(r *DiscoveredCluster) *DiscoveryImportPolicy {
+	name := r.GetAnnotations()[ImportPolicyAnnotation]
+	if Client == nil || name == "" {
+		return nil
+	}
+
+	policy := &DiscoveryImportPolicy{}
+	if err := Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: r.Namespace}, policy); err != nil {
+		return nil
+	}
+	return policy
+}
+
+// NormalizeName converts the name into an RFC 1123 label of at most maxLength characters.
+func NormalizeName(name string, maxLength int) string {
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	return name
+}
+
 // IsSupportedClusterType returns true if the cluster type is supported by the registry
 func IsSupportedClusterType(clusterType string) bool {
 	supportedTypes := map[string]bool{
@@ -169,11 +491,24 @@ func IsSupportedClusterType(clusterType string) bool {
 		"ROSA":                  true,
 	}
 
-	return supportedTypes[clusterType]
+	return supportedTypes[clusterType] || IsCredentialImportClusterType(clusterType)
+}
+
+// IsCredentialImportClusterType returns true if clusters of the type are imported with a user-supplied credential
+func IsCredentialImportClusterType(clusterType string) bool {
+	credentialImportTypes := map[string]bool{
+		"ARO":                 true,
+		"OCP":                 true,
+		"OCP-AssistedInstall": true,
+		"OSD":                 true,
+		"OSDTrial":            true,
+	}
+
+	return credentialImportTypes[clusterType]
 }
 
-// IsStringValid returns true if the string conforms to the RFC 1123 standards.
+// IsStringValid returns true if the string is an RFC 1123 label, which is required for ManagedCluster and namespace
+// names.
 func IsStringValid(s string) bool {
-	re := regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
-	return re.MatchString(s)
+	return len(validation.IsDNS1123Label(s)) == 0
 }
@@ -0,0 +1,302 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         string
+		maxLength int
+		want      string
+	}{
+		{name: "Keeps valid names", s: "my-cluster", maxLength: 63, want: "my-cluster"},
+		{name: "Lowercases names", s: "My-Cluster", maxLength: 63, want: "my-cluster"},
+		{name: "Replaces invalid characters", s: "my_cluster.prod!", maxLength: 63, want: "my-cluster-prod"},
+		{name: "Truncates long names", s: "abcdefghij-klmnop", maxLength: 11, want: "abcdefghij"},
+		{name: "Returns an empty name", s: "__", maxLength: 63, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.s, tt.maxLength); got != tt.want {
+				t.Errorf("NormalizeName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoveredCluster_Default(t *testing.T) {
+	config := &DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        DefaultDiscoveryConfigName,
+			Namespace:   "bar",
+			Annotations: map[string]string{ImportStrategyAnnotation: ImportStrategyManual},
+		},
+		Spec: DiscoveryConfigSpec{
+			ManagedCluster: &ManagedClusterSettings{ClusterSet: "staging", KlusterletConfig: "proxy"},
+		},
+	}
+	policy := &DiscoveryImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "rosa", Namespace: "bar"},
+		Spec:       DiscoveryImportPolicySpec{ManagedClusterSet: "rosa"},
+	}
+
+	tests := []struct {
+		name                 string
+		dc                   *DiscoveredCluster
+		wantDisplayName      string
+		wantStrategy         string
+		wantClusterSet       string
+		wantKlusterletConfig string
+	}{
+		{
+			name: "Leaves clusters that are not imported unchanged",
+			dc: &DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec:       DiscoveredClusterSpec{DisplayName: "My_Cluster"},
+			},
+			wantDisplayName: "My_Cluster",
+		},
+		{
+			name: "Applies the defaults of the DiscoveryConfig without changing the display name",
+			dc: &DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec:       DiscoveredClusterSpec{DisplayName: "My_Cluster", ImportAsManagedCluster: true},
+			},
+			wantDisplayName:      "My_Cluster",
+			wantStrategy:         ImportStrategyManual,
+			wantClusterSet:       "staging",
+			wantKlusterletConfig: "proxy",
+		},
+		{
+			name: "Applies the ManagedClusterSet of the DiscoveryImportPolicy",
+			dc: &DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "bar",
+					Annotations: map[string]string{ImportPolicyAnnotation: "rosa"},
+				},
+				Spec: DiscoveredClusterSpec{DisplayName: "foo", ImportAsManagedCluster: true},
+			},
+			wantDisplayName:      "foo",
+			wantStrategy:         ImportStrategyManual,
+			wantClusterSet:       "rosa",
+			wantKlusterletConfig: "proxy",
+		},
+		{
+			name: "Keeps the settings of the DiscoveredCluster",
+			dc: &DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "bar",
+					Annotations: map[string]string{ImportStrategyAnnotation: ImportStrategyAutomatic},
+				},
+				Spec: DiscoveredClusterSpec{
+					DisplayName:            "foo",
+					ImportAsManagedCluster: true,
+					ManagedCluster:         &ManagedClusterSettings{ClusterSet: "prod"},
+				},
+			},
+			wantDisplayName:      "foo",
+			wantStrategy:         ImportStrategyAutomatic,
+			wantClusterSet:       "prod",
+			wantKlusterletConfig: "proxy",
+		},
+	}
+
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+	Client = fake.NewClientBuilder().WithScheme(s).WithObjects(config, policy).Build()
+	defer func() { Client = nil }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.dc.Default()
+
+			if tt.dc.Spec.DisplayName != tt.wantDisplayName {
+				t.Errorf("displayName = %q, want %q", tt.dc.Spec.DisplayName, tt.wantDisplayName)
+			}
+			if got := tt.dc.GetAnnotations()[ImportStrategyAnnotation]; got != tt.wantStrategy {
+				t.Errorf("import strategy = %q, want %q", got, tt.wantStrategy)
+			}
+
+			settings := ManagedClusterSettings{}
+			if tt.dc.Spec.ManagedCluster != nil {
+				settings = *tt.dc.Spec.ManagedCluster
+			}
+			if settings.ClusterSet != tt.wantClusterSet || settings.KlusterletConfig != tt.wantKlusterletConfig {
+				t.Errorf("managedCluster = %+v, want clusterSet %q and klusterletConfig %q", settings,
+					tt.wantClusterSet, tt.wantKlusterletConfig)
+			}
+		})
+	}
+}
+
+func TestDiscoveredClusterValidator_ValidateUpdate(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "open-cluster-management")
+	t.Setenv("POD_SERVICE_ACCOUNT", "discovery-operator")
+
+	old := &DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+		Spec: DiscoveredClusterSpec{
+			APIURL:      "https://api.foo.example.com:6443",
+			DisplayName: "My-Cluster",
+			Type:        "ROSA",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		mutate  func(*DiscoveredCluster)
+		wantErr string
+	}{
+		{
+			name:   "Allows users to approve the import",
+			user:   "kube:admin",
+			mutate: func(dc *DiscoveredCluster) { dc.Spec.ImportApproved = true },
+		},
+		{
+			name:   "Allows importing clusters whose display name is not a valid ManagedCluster name",
+			user:   "kube:admin",
+			mutate: func(dc *DiscoveredCluster) { dc.Spec.ImportAsManagedCluster = true },
+		},
+
+		{
+			name:    "Rejects users changing the API URL",
+			user:    "kube:admin",
+			mutate:  func(dc *DiscoveredCluster) { dc.Spec.APIURL = "https://api.bar.example.com:6443" },
+			wantErr: "spec.apiUrl is synced from OCM",
+		},
+		{
+			name:    "Rejects users changing the display name",
+			user:    "kube:admin",
+			mutate:  func(dc *DiscoveredCluster) { dc.Spec.DisplayName = "other" },
+			wantErr: "spec.displayName is synced from OCM",
+		},
+		{
+			name:    "Rejects service accounts of other namespaces",
+			user:    "system:serviceaccount:other:discovery-operator",
+			mutate:  func(dc *DiscoveredCluster) { dc.Spec.Type = "OCP" },
+			wantErr: "spec.type is synced from OCM",
+		},
+		{
+			name:    "Rejects other service accounts of the operator namespace",
+			user:    "system:serviceaccount:open-cluster-management:default",
+			mutate:  func(dc *DiscoveredCluster) { dc.Spec.Type = "OCP" },
+			wantErr: "spec.type is synced from OCM",
+		},
+		{
+			name:   "Allows the operator to sync fields from OCM",
+			user:   "system:serviceaccount:open-cluster-management:discovery-operator",
+			mutate: func(dc *DiscoveredCluster) { dc.Spec.APIURL = "https://api.bar.example.com:6443" },
+		},
+	}
+
+	v := &discoveredClusterValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := old.DeepCopy()
+			tt.mutate(dc)
+
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: tt.user},
+			}}
+			_, err := v.ValidateUpdate(admission.NewContextWithRequest(context.TODO(), req), old, dc)
+
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateUpdate() error = %v, want no error", err)
+			} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateUpdate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiscoveredCluster_statusWarnings(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldStatus   string
+		status      string
+		wantWarning bool
+	}{
+		{name: "Accepts known statuses without a warning", status: SubscriptionStatusDisconnected},
+		{name: "Accepts an empty status without a warning", status: ""},
+		{name: "Warns about unknown statuses", status: "Suspended", wantWarning: true},
+		{name: "Does not warn again when the status is unchanged", oldStatus: "Suspended", status: "Suspended"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec:       DiscoveredClusterSpec{DisplayName: "foo", Type: "ROSA", Status: tt.status},
+			}
+
+			var warnings admission.Warnings
+			var err error
+			if tt.oldStatus == "" {
+				warnings, err = dc.ValidateCreate()
+			} else {
+				old := dc.DeepCopy()
+				old.Spec.Status = tt.oldStatus
+				warnings, err = dc.ValidateUpdate(old)
+			}
+			if err != nil {
+				t.Fatalf("validation error = %v, want no error", err)
+			}
+
+			gotWarning := len(warnings) > 0 && strings.Contains(warnings[0], "unknown subscription status")
+			if gotWarning != tt.wantWarning {
+				t.Errorf("warnings = %v, want warning %v", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestIsStringValid(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "my-cluster", want: true},
+		{s: "My-Cluster", want: false},
+		{s: "-my-cluster", want: false},
+		{s: "my_cluster", want: false},
+		{s: strings.Repeat("a", MaxManagedClusterNameLength), want: true},
+		{s: strings.Repeat("a", MaxManagedClusterNameLength+1), want: false},
+	}
+	for _, tt := range tests {
+		if got := IsStringValid(tt.s); got != tt.want {
+			t.Errorf("IsStringValid(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -18,9 +18,26 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DefaultDiscoveryConfigName is the name of the DiscoveryConfig that discovers clusters in a namespace.
+const DefaultDiscoveryConfigName = "discovery"
+
+// DefaultTrialExpiryWindow is the expiry window used when a DiscoveryConfig does not set one.
+const DefaultTrialExpiryWindow = 7 * 24 * time.Hour
+
+// DefaultHostedAddOnInstallNamespace is the namespace where the hosted addon agents are installed when a
+// DiscoveryConfig does not set one.
+const DefaultHostedAddOnInstallNamespace = "open-cluster-management-discovered-hcp"
+
+// DefaultHostedAddOns are the addons installed on imported MultiClusterEngineHCP clusters when a DiscoveryConfig does
+// not set them.
+var DefaultHostedAddOns = []string{"cluster-proxy", "managed-serviceaccount", "work-manager"}
+
 // Filter defines the criteria for discovering clusters based on specific attributes.
 type Filter struct {
 	// ClusterTypes is the list of cluster types to discover. These types represent the platform
@@ -47,6 +64,12 @@ type Filter struct {
 	// requirements.
 	// +optional
 	Regions []string `json:"regions,omitempty"`
+
+	// IncludeUnreported includes clusters that do not report telemetry metrics, such as disconnected or air-gapped
+	// clusters and clusters that are still being installed (Reserved). These clusters are discovered without an
+	// OpenShift version and with an Unknown Available condition.
+	// +optional
+	IncludeUnreported bool `json:"includeUnreported,omitempty"`
 }
 
 // Semver represents a partial semver string with the major and minor version
@@ -54,6 +77,102 @@ type Filter struct {
 // +kubebuilder:validation:Pattern="^(?:0|[1-9]\\d*)\\.(?:0|[1-9]\\d*)$"
 type Semver string
 
+// ImportCredentialMapping maps a DiscoveredCluster to the Secret used to import it.
+type ImportCredentialMapping struct {
+	// ClusterName is the display name of the DiscoveredCluster.
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// SecretName is the name of a Secret in the namespace of the DiscoveryConfig. The Secret contains either a
+	// kubeconfig key, or token and server keys.
+	// +required
+	SecretName string `json:"secretName"`
+}
+
+// ManagedClusterSettings configures the ManagedClusters that are created to import DiscoveredClusters.
+type ManagedClusterSettings struct {
+	// ClusterSet is the ManagedClusterSet that the ManagedCluster is added to. ManagedClusters are added to the
+	// default ManagedClusterSet when it is not set.
+	// +optional
+	ClusterSet string `json:"clusterSet,omitempty"`
+
+	// Labels are additional labels set on the ManagedCluster. They cannot replace the name label or the labels that
+	// identify the resources created by the discovery operator.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are additional annotations set on the ManagedCluster. They cannot replace the annotations set by
+	// the discovery operator.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// KlusterletConfig is the name of the KlusterletConfig used to deploy the klusterlet on the cluster, for
+	// example to configure a proxy or a mirrored registry. The import fails while the KlusterletConfig does not
+	// exist.
+	// +optional
+	KlusterletConfig string `json:"klusterletConfig,omitempty"`
+}
+
+/*
+KlusterletAddonConfigTemplate is merged into the KlusterletAddonConfigs created to import DiscoveredClusters. Addons
+that are not set keep their default and are enabled.
+*/
+type KlusterletAddonConfigTemplate struct {
+	// ApplicationManager enables the application manager addon.
+	// +optional
+	ApplicationManager *bool `json:"applicationManager,omitempty"`
+
+	// CertPolicyController enables the certificate policy controller addon.
+	// +optional
+	CertPolicyController *bool `json:"certPolicyController,omitempty"`
+
+	// PolicyController enables the policy controller addon.
+	// +optional
+	PolicyController *bool `json:"policyController,omitempty"`
+
+	// SearchCollector enables the search collector addon.
+	// +optional
+	SearchCollector *bool `json:"searchCollector,omitempty"`
+
+	// ClusterLabels are merged into the cluster labels of the KlusterletAddonConfig. The name label cannot be
+	// replaced.
+	// +optional
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+}
+
+/*
+HostedAddOnSettings configures the rollout of addons to imported MultiClusterEngineHCP clusters. The addons are
+installed in hosted mode through a Placement that selects the hosted clusters and an AddOnDeploymentConfig.
+*/
+type HostedAddOnSettings struct {
+	// AddOns are the names of the ClusterManagementAddOns installed on the hosted clusters. Defaults to cluster-proxy,
+	// managed-serviceaccount and work-manager.
+	// +optional
+	AddOns []string `json:"addOns,omitempty"`
+
+	// PlacementName is the name of the Placement that selects the hosted clusters. Defaults to default.
+	// +optional
+	PlacementName string `json:"placementName,omitempty"`
+
+	// PlacementNamespace is the namespace of the Placement that selects the hosted clusters. Defaults to the namespace
+	// of the discovery operator.
+	// +optional
+	PlacementNamespace string `json:"placementNamespace,omitempty"`
+
+	// AgentInstallNamespace is the namespace where the addon agents are installed. Defaults to
+	// open-cluster-management-discovered-hcp.
+	// +optional
+	AgentInstallNamespace string `json:"agentInstallNamespace,omitempty"`
+
+	// NodeSelector selects the nodes that the addon agents are scheduled on.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are the tolerations of the addon agents.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
 // DiscoveryConfigSpec defines the desired state of DiscoveryConfig
 type DiscoveryConfigSpec struct {
 	// Credential is the secret containing credentials to connect to the OCM api on behalf of a user
@@ -63,6 +182,38 @@ type DiscoveryConfigSpec struct {
 	// Sets restrictions on what kind of clusters to discover
 	// +optional
 	Filters Filter `json:"filters,omitempty"`
+
+	// TrialExpiryWindow is how long before the end of a trial subscription the DiscoveredCluster reports the
+	// ExpiringSoon condition. Defaults to 168h (7 days).
+	// +optional
+	TrialExpiryWindow *metav1.Duration `json:"trialExpiryWindow,omitempty"`
+
+	// StaleAfter is how long after the last observed activity a DiscoveredCluster is considered stale. When set, the
+	// Available condition is computed from the activity timestamp of the cluster, and the status reported by OCM is
+	// reported in the OCMAvailable condition. It must be positive.
+	// +optional
+	StaleAfter *metav1.Duration `json:"staleAfter,omitempty"`
+
+	// ImportCredentials maps DiscoveredClusters to the Secrets used to import self-managed clusters that do not
+	// reference an import credential themselves.
+	// +optional
+	ImportCredentials []ImportCredentialMapping `json:"importCredentials,omitempty"`
+
+	// ManagedCluster configures the ManagedClusters created to import the DiscoveredClusters in the namespace.
+	// DiscoveredClusters and DiscoveryImportPolicies can override these settings.
+	// +optional
+	ManagedCluster *ManagedClusterSettings `json:"managedCluster,omitempty"`
+
+	// KlusterletAddonConfigTemplate selects the addons enabled for the DiscoveredClusters imported in the namespace.
+	// Changes are reconciled onto the KlusterletAddonConfigs created by the discovery operator.
+	// +optional
+	KlusterletAddonConfigTemplate *KlusterletAddonConfigTemplate `json:"klusterletAddonConfigTemplate,omitempty"`
+
+	// HostedAddOns configures the addons installed on the MultiClusterEngineHCP clusters imported by the operator. The
+	// addons are configured for the whole hub, so every DiscoveryConfig that sets HostedAddOns must set the same value.
+	// Changes are reconciled onto the AddOnDeploymentConfig and the ClusterManagementAddOns.
+	// +optional
+	HostedAddOns *HostedAddOnSettings `json:"hostedAddOns,omitempty"`
 }
 
 // DiscoveryConfigStatus defines the observed state of DiscoveryConfig
@@ -0,0 +1,302 @@
+// Copyright Contributors to the Open Cluster Management project
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stolostron/discovery/pkg/ocm/auth"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	cl "sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var discoveryconfigLog = logf.Log.WithName("discoveryconfig-resource")
+
+const (
+	// discoveryRefreshInterval is how often the DiscoveryConfig controller refreshes the DiscoveredClusters.
+	discoveryRefreshInterval = 20 * time.Minute
+
+	// largeOrganizationClusterCount is the number of DiscoveredClusters from which an organization is considered large.
+	largeOrganizationClusterCount = 1000
+)
+
+// FilterClusterTypes are the cluster types, as OCM subscription plans, that the filters of a DiscoveryConfig accept.
+var FilterClusterTypes = []string{
+	"ARO", "MOA", "MOA-HostedControlPlane", "OCP", "OCP-AssistedInstall", "OSD", "OSDTrial", "RHMI", "RHOIC", "ROSA",
+	"ROSA-HyperShift",
+}
+
+// FilterInfrastructureProviders are the infrastructure providers that the filters of a DiscoveryConfig accept.
+var FilterInfrastructureProviders = []string{
+	"alibabacloud", "aws", "azure", "baremetal", "external", "gcp", "ibmcloud", "libvirt", "none", "nutanix",
+	"openstack", "ovirt", "powervs", "vsphere",
+}
+
+var regionFormat = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func (r *DiscoveryConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	Client = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+var _ webhook.Validator = &DiscoveryConfig{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *DiscoveryConfig) ValidateCreate() (admission.Warnings, error) {
+	discoveryconfigLog.Info("validate create", "Name", r.Name, "Namespace", r.Namespace)
+
+	warnings, err := r.validate(true, true, true)
+	if err != nil {
+		err = fmt.Errorf("cannot create DiscoveryConfig '%s': %w", r.Name, err)
+		discoveryconfigLog.Error(err, "validation failed")
+	}
+	return warnings, err
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *DiscoveryConfig) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	discoveryconfigLog.Info("validate update", "Name", r.Name, "Namespace", r.Namespace)
+
+	// DiscoveryConfigs that are being deleted can still be updated, for example to remove their finalizers.
+	if !r.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+
+	/*
+		Only check the credential, the filters and the hosted addons when they change, so that the DiscoveryConfig can
+		still be updated while its Secret is missing, its filters use a value that is no longer known, or another
+		DiscoveryConfig conflicts with it.
+	*/
+	oldDiscoveryConfig := old.(*DiscoveryConfig)
+	warnings, err := r.validate(r.Spec.Credential != oldDiscoveryConfig.Spec.Credential,
+		!equality.Semantic.DeepEqual(r.Spec.Filters, oldDiscoveryConfig.Spec.Filters),
+		!equality.Semantic.DeepEqual(r.Spec.HostedAddOns, oldDiscoveryConfig.Spec.HostedAddOns))
+	if err != nil {
+		err = fmt.Errorf("cannot update DiscoveryConfig '%s': %w", r.Name, err)
+		discoveryconfigLog.Error(err, "validation failed")
+	}
+	return warnings, err
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *DiscoveryConfig) ValidateDelete() (admission.Warnings, error) {
+	discoveryconfigLog.Info("validate delete", "Name", r.Name, "Namespace", r.Namespace)
+	return nil, nil
+}
+
+/*
+validate returns the errors in the DiscoveryConfig, and warnings for settings that are valid but risky. The credential
+Secret is only checked when checkCredential is true and the Secret can be read, the filters are only checked when
+checkFilters is true, and the hosted addons are only checked against the other DiscoveryConfigs when checkHostedAddOns
+is true.
+*/
+func (r *DiscoveryConfig) validate(checkCredential, checkFilters, checkHostedAddOns bool) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var errs []error
+
+	if r.Name != DefaultDiscoveryConfigName {
+		errs = append(errs, fmt.Errorf("the name must be '%s'", DefaultDiscoveryConfigName))
+	}
+
+	if strategy, found := r.GetAnnotations()[ImportStrategyAnnotation]; found && !IsValidImportStrategy(strategy) {
+		errs = append(errs, fmt.Errorf("invalid %s annotation '%s', it must be %s, %s or %s",
+			ImportStrategyAnnotation, strategy, ImportStrategyAutomatic, ImportStrategyManual, ImportStrategyDisabled))
+	}
+
+	if checkCredential {
+		warning, err := r.validateCredential()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	if checkFilters {
+		errs = append(errs, validateFilter(r.Spec.Filters)...)
+	}
+
+	if window := r.Spec.TrialExpiryWindow; window != nil && window.Duration < 0 {
+		errs = append(errs, fmt.Errorf("trialExpiryWindow must not be negative"))
+	}
+
+	if staleAfter := r.Spec.StaleAfter; staleAfter != nil {
+		if staleAfter.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("staleAfter must be positive"))
+		} else if staleAfter.Duration < discoveryRefreshInterval {
+			warnings = append(warnings, fmt.Sprintf("staleAfter %s is shorter than the discovery refresh interval "+
+				"of %s, so active clusters can be reported as stale", staleAfter.Duration, discoveryRefreshInterval))
+		}
+	}
+
+	secrets := map[string]string{}
+	for _, mapping := range r.Spec.ImportCredentials {
+		if secret, found := secrets[mapping.ClusterName]; found && secret != mapping.SecretName {
+			errs = append(errs, fmt.Errorf("importCredentials maps cluster '%s' to both Secret '%s' and Secret '%s'",
+				mapping.ClusterName, secret, mapping.SecretName))
+		}
+		secrets[mapping.ClusterName] = mapping.SecretName
+	}
+
+	if checkHostedAddOns {
+		if err := r.validateHostedAddOns(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if isEmptyFilter(r.Spec.Filters) {
+		warning := "filters are empty, so every cluster of the OCM organization is discovered"
+		if count := r.countDiscoveredClusters(); count >= largeOrganizationClusterCount {
+			warning = fmt.Sprintf("%s. The namespace already contains %d DiscoveredClusters, so discovery may be slow "+
+				"and create many resources on the hub", warning, count)
+		}
+		warnings = append(warnings, warning)
+	}
+
+	return warnings, utilerrors.NewAggregate(errs)
+}
+
+/*
+validateCredential returns an error if the credential Secret does not exist or cannot be parsed. A warning is returned
+instead when the Secret cannot be read for another reason, so that the DiscoveryConfig is not rejected because of a
+transient error.
+*/
+func (r *DiscoveryConfig) validateCredential() (string, error) {
+	if r.Spec.Credential == "" {
+		return "", fmt.Errorf("credential must be set")
+	}
+
+	if Client == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	nn := types.NamespacedName{Name: r.Spec.Credential, Namespace: r.Namespace}
+	if err := Client.Get(context.TODO(), nn, secret); apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("credential Secret '%s' does not exist", r.Spec.Credential)
+	} else if err != nil {
+		return fmt.Sprintf("credential Secret '%s' could not be verified: %v", r.Spec.Credential, err), nil
+	}
+
+	if _, err := auth.ParseSecretForAuth(secret); err != nil {
+		return "", fmt.Errorf("invalid credential Secret: %w", err)
+	}
+	return "", nil
+}
+
+/*
+validateHostedAddOns returns an error if another DiscoveryConfig sets different hosted addon settings. The hosted addons
+are configured for the whole hub, so DiscoveryConfigs with different settings would keep overwriting each other.
+*/
+func (r *DiscoveryConfig) validateHostedAddOns() error {
+	if r.Spec.HostedAddOns == nil || Client == nil {
+		return nil
+	}
+
+	configs := &DiscoveryConfigList{}
+	if err := Client.List(context.TODO(), configs); err != nil {
+		discoveryconfigLog.Error(err, "failed to list DiscoveryConfigs")
+		return nil
+	}
+
+	for _, config := range configs.Items {
+		if config.Namespace == r.Namespace || config.Spec.HostedAddOns == nil {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(config.Spec.HostedAddOns, r.Spec.HostedAddOns) {
+			return fmt.Errorf("hostedAddOns must match the hostedAddOns of DiscoveryConfig '%s' in namespace '%s', "+
+				"since the hosted addons are configured for the whole hub", config.Name, config.Namespace)
+		}
+	}
+	return nil
+}
+
+// countDiscoveredClusters returns the number of DiscoveredClusters in the namespace of the DiscoveryConfig, or 0 if
+// they cannot be listed.
+func (r *DiscoveryConfig) countDiscoveredClusters() int {
+	if Client == nil {
+		return 0
+	}
+
+	discovered := &DiscoveredClusterList{}
+	if err := Client.List(context.TODO(), discovered, cl.InNamespace(r.Namespace)); err != nil {
+		return 0
+	}
+	return len(discovered.Items)
+}
+
+// validateFilter returns the errors in the filters of a DiscoveryConfig.
+func validateFilter(f Filter) []error {
+	var errs []error
+
+	for _, clusterType := range f.ClusterTypes {
+		if !slices.Contains(FilterClusterTypes, clusterType) {
+			errs = append(errs, fmt.Errorf("invalid cluster type '%s' in filters, it must be one of %s",
+				clusterType, strings.Join(FilterClusterTypes, ", ")))
+		}
+	}
+
+	for _, provider := range f.InfrastructureProviders {
+		if !slices.Contains(FilterInfrastructureProviders, provider) {
+			errs = append(errs, fmt.Errorf("invalid infrastructure provider '%s' in filters, it must be one of %s",
+				provider, strings.Join(FilterInfrastructureProviders, ", ")))
+		}
+	}
+
+	for _, region := range f.Regions {
+		if !regionFormat.MatchString(region) {
+			errs = append(errs, fmt.Errorf("invalid region '%s' in filters, it must consist of lowercase "+
+				"alphanumeric characters separated by '-'", region))
+		}
+	}
+
+	if f.LastActive < 0 {
+		errs = append(errs, fmt.Errorf("lastActive must not be negative"))
+	}
+
+	// Clusters that do not report telemetry have no OpenShift version, so they never match a version filter.
+	if f.IncludeUnreported && len(f.OpenShiftVersions) > 0 {
+		errs = append(errs, fmt.Errorf("includeUnreported cannot be combined with openShiftVersions, since "+
+			"unreported clusters have no OpenShift version"))
+	}
+
+	return errs
+}
+
+// isEmptyFilter returns true if the filters do not restrict which clusters are discovered.
+func isEmptyFilter(f Filter) bool {
+	return len(f.ClusterTypes) == 0 && len(f.InfrastructureProviders) == 0 && len(f.OpenShiftVersions) == 0 &&
+		len(f.Regions) == 0 && f.LastActive == 0
+}
@@ -0,0 +1,238 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiscoveryConfig_ValidateCreate(t *testing.T) {
+	objs := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocm-token", Namespace: "bar"},
+			Data:       map[string][]byte{"ocmAPIToken": []byte("token")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bad-token", Namespace: "bar"},
+			Data:       map[string][]byte{"auth_method": []byte("service-account")},
+		},
+		&DiscoveryConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultDiscoveryConfigName, Namespace: "other"},
+			Spec: DiscoveryConfigSpec{
+				Credential:   "ocm-token",
+				HostedAddOns: &HostedAddOnSettings{AgentInstallNamespace: "hosted-agents"},
+			},
+		},
+	}
+
+	newConfig := func(mutate func(*DiscoveryConfig)) *DiscoveryConfig {
+		config := &DiscoveryConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultDiscoveryConfigName, Namespace: "bar"},
+			Spec: DiscoveryConfigSpec{
+				Credential: "ocm-token",
+				Filters:    Filter{LastActive: 7},
+			},
+		}
+		if mutate != nil {
+			mutate(config)
+		}
+		return config
+	}
+
+	tests := []struct {
+		name        string
+		config      *DiscoveryConfig
+		wantErr     string
+		wantWarning string
+	}{
+		{
+			name:   "Accepts a valid DiscoveryConfig",
+			config: newConfig(nil),
+		},
+		{
+			name:    "Rejects an invalid name",
+			config:  newConfig(func(c *DiscoveryConfig) { c.Name = "other" }),
+			wantErr: "the name must be 'discovery'",
+		},
+		{
+			name:    "Rejects a missing credential Secret",
+			config:  newConfig(func(c *DiscoveryConfig) { c.Spec.Credential = "missing" }),
+			wantErr: "credential Secret 'missing' does not exist",
+		},
+		{
+			name:    "Rejects a credential Secret that cannot be parsed",
+			config:  newConfig(func(c *DiscoveryConfig) { c.Spec.Credential = "bad-token" }),
+			wantErr: "secret must contain client_id and client_secret",
+		},
+		{
+			name: "Rejects an invalid import strategy",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Annotations = map[string]string{ImportStrategyAnnotation: "Sometimes"}
+			}),
+			wantErr: "invalid discovery.open-cluster-management.io/import-strategy annotation 'Sometimes'",
+		},
+		{
+			name:    "Rejects an invalid cluster type",
+			config:  newConfig(func(c *DiscoveryConfig) { c.Spec.Filters.ClusterTypes = []string{"ROSA", "rosa"} }),
+			wantErr: "invalid cluster type 'rosa'",
+		},
+		{
+			name: "Rejects an invalid infrastructure provider",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.Filters.InfrastructureProviders = []string{"AWS"}
+			}),
+			wantErr: "invalid infrastructure provider 'AWS'",
+		},
+		{
+			name:    "Rejects an invalid region",
+			config:  newConfig(func(c *DiscoveryConfig) { c.Spec.Filters.Regions = []string{"us-east-1", "US East"} }),
+			wantErr: "invalid region 'US East'",
+		},
+		{
+			name: "Rejects unreported clusters filtered by version",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.Filters.IncludeUnreported = true
+				c.Spec.Filters.OpenShiftVersions = []Semver{"4.16"}
+			}),
+			wantErr: "includeUnreported cannot be combined with openShiftVersions",
+		},
+		{
+			name: "Rejects conflicting import credentials",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.ImportCredentials = []ImportCredentialMapping{
+					{ClusterName: "foo", SecretName: "a"},
+					{ClusterName: "foo", SecretName: "b"},
+				}
+			}),
+			wantErr: "importCredentials maps cluster 'foo' to both Secret 'a' and Secret 'b'",
+		},
+		{
+			name: "Accepts the hosted addons of the other DiscoveryConfigs",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.HostedAddOns = &HostedAddOnSettings{AgentInstallNamespace: "hosted-agents"}
+			}),
+		},
+		{
+			name: "Rejects hosted addons that conflict with another DiscoveryConfig",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.HostedAddOns = &HostedAddOnSettings{AgentInstallNamespace: "agents"}
+			}),
+			wantErr: "hostedAddOns must match the hostedAddOns of DiscoveryConfig 'discovery' in namespace 'other'",
+		},
+		{
+			name: "Rejects a negative staleAfter",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.StaleAfter = &metav1.Duration{Duration: -time.Hour}
+			}),
+			wantErr: "staleAfter must be positive",
+		},
+		{
+			name: "Rejects a zero staleAfter",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.StaleAfter = &metav1.Duration{Duration: 0}
+			}),
+			wantErr: "staleAfter must be positive",
+		},
+		{
+			name: "Warns about a short staleAfter",
+			config: newConfig(func(c *DiscoveryConfig) {
+				c.Spec.StaleAfter = &metav1.Duration{Duration: 5 * time.Minute}
+			}),
+			wantWarning: "shorter than the discovery refresh interval",
+		},
+		{
+			name:        "Warns about empty filters",
+			config:      newConfig(func(c *DiscoveryConfig) { c.Spec.Filters = Filter{} }),
+			wantWarning: "every cluster of the OCM organization is discovered",
+		},
+	}
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+	if err := AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+	Client = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+	defer func() { Client = nil }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := tt.config.ValidateCreate()
+
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateCreate() error = %v, want no error", err)
+			} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateCreate() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if got := strings.Join(warnings, "\n"); tt.wantWarning == "" && got != "" {
+				t.Errorf("ValidateCreate() warnings = %q, want none", got)
+			} else if !strings.Contains(got, tt.wantWarning) {
+				t.Errorf("ValidateCreate() warnings = %q, want %q", got, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestDiscoveryConfig_ValidateUpdate(t *testing.T) {
+	old := &DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultDiscoveryConfigName, Namespace: "bar"},
+		Spec:       DiscoveryConfigSpec{Credential: "deleted", Filters: Filter{LastActive: 7}},
+	}
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+	Client = fake.NewClientBuilder().WithScheme(s).Build()
+	defer func() { Client = nil }()
+
+	updated := old.DeepCopy()
+	updated.Spec.Filters.Regions = []string{"us-east-1"}
+	if _, err := updated.ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want no error when the credential is unchanged", err)
+	}
+
+	updated.Spec.Credential = "missing"
+	if _, err := updated.ValidateUpdate(old); err == nil {
+		t.Errorf("ValidateUpdate() error = nil, want an error when the new credential does not exist")
+	}
+
+	// Filters with values that are no longer known do not block unrelated updates.
+	old.Spec.Filters.ClusterTypes = []string{"UNKNOWN"}
+	updated = old.DeepCopy()
+	updated.Annotations = map[string]string{"foo": "bar"}
+	if _, err := updated.ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want no error when the filters are unchanged", err)
+	}
+
+	updated.Spec.Filters.LastActive = 14
+	if _, err := updated.ValidateUpdate(old); err == nil {
+		t.Errorf("ValidateUpdate() error = nil, want an error when the changed filters are invalid")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultImportRateLimitPeriod is the rate limit period used when a DiscoveryImportPolicy does not set one.
+const DefaultImportRateLimitPeriod = time.Hour
+
+const (
+	// ImportPolicyAnnotation is the annotation naming the DiscoveryImportPolicy that triggered the import of a
+	// DiscoveredCluster.
+	ImportPolicyAnnotation = "discovery.open-cluster-management.io/import-policy"
+
+	// ImportPolicyTimestampAnnotation is the annotation recording when a DiscoveryImportPolicy triggered the import of
+	// a DiscoveredCluster, in RFC 3339 format.
+	ImportPolicyTimestampAnnotation = "discovery.open-cluster-management.io/import-policy-timestamp"
+)
+
+// ImportPolicyClusterSelector selects DiscoveredClusters by their attributes. All criteria that are set must match.
+type ImportPolicyClusterSelector struct {
+	// ClusterTypes is the list of cluster types to import (e.g. ROSA, OCP, MultiClusterEngineHCP).
+	// +optional
+	ClusterTypes []string `json:"clusterTypes,omitempty"`
+
+	// InfrastructureProviders is the list of cloud providers of the clusters to import (e.g. aws, azure, gcp).
+	// +optional
+	InfrastructureProviders []string `json:"infrastructureProviders,omitempty"`
+
+	// OpenShiftVersions is the list of release versions of OpenShift of the form "<Major>.<Minor>"
+	// +optional
+	OpenShiftVersions []Semver `json:"openShiftVersions,omitempty"`
+
+	// Regions is the list of regions of the clusters to import.
+	// +optional
+	Regions []string `json:"regions,omitempty"`
+
+	// LabelSelector selects DiscoveredClusters by their labels.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// NamePattern is a regular expression that the display name of the DiscoveredCluster must match.
+	// +optional
+	NamePattern string `json:"namePattern,omitempty"`
+}
+
+// ImportRateLimit limits how many imports a DiscoveryImportPolicy triggers in a period of time.
+type ImportRateLimit struct {
+	// MaxImports is the maximum number of imports triggered in a period.
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	MaxImports int32 `json:"maxImports"`
+
+	// Period is the period over which imports are counted. Defaults to 1h.
+	// +optional
+	Period *metav1.Duration `json:"period,omitempty"`
+}
+
+// DiscoveryImportPolicySpec defines the desired state of DiscoveryImportPolicy
+type DiscoveryImportPolicySpec struct {
+	// ClusterSelector selects the DiscoveredClusters in the namespace of the policy that are imported. An empty
+	// selector selects all DiscoveredClusters.
+	// +optional
+	ClusterSelector ImportPolicyClusterSelector `json:"clusterSelector,omitempty"`
+
+	// RateLimit limits how many imports the policy triggers. Imports are not limited when it is not set.
+	// +optional
+	RateLimit *ImportRateLimit `json:"rateLimit,omitempty"`
+
+	// ManagedClusterSet is the ManagedClusterSet that the imported clusters are added to.
+	// +optional
+	ManagedClusterSet string `json:"managedClusterSet,omitempty"`
+
+	// ManagedClusterLabels are the labels set on the ManagedClusters of the imported clusters.
+	// +optional
+	ManagedClusterLabels map[string]string `json:"managedClusterLabels,omitempty"`
+
+	// KlusterletAddonConfigTemplate overrides the template of the DiscoveryConfig for the imported clusters.
+	// +optional
+	KlusterletAddonConfigTemplate *KlusterletAddonConfigTemplate `json:"klusterletAddonConfigTemplate,omitempty"`
+}
+
+// DiscoveryImportPolicyStatus defines the observed state of DiscoveryImportPolicy
+type DiscoveryImportPolicyStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Clusterset",type=string,JSONPath=`.spec.managedClusterSet`
+//+kubebuilder:printcolumn:name="Max Imports",type=integer,JSONPath=`.spec.rateLimit.maxImports`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DiscoveryImportPolicy is the Schema for the discoveryimportpolicies API
+type DiscoveryImportPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiscoveryImportPolicySpec   `json:"spec,omitempty"`
+	Status DiscoveryImportPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DiscoveryImportPolicyList contains a list of DiscoveryImportPolicy
+type DiscoveryImportPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiscoveryImportPolicy `json:"items"`
+}
+
+/*
+Matches returns true if the DiscoveredCluster is selected by the cluster selector of the policy. An error is returned
+if the label selector or the name pattern of the policy is invalid.
+*/
+func (p *DiscoveryImportPolicy) Matches(dc *DiscoveredCluster) (bool, error) {
+	s := p.Spec.ClusterSelector
+
+	if !matchesAny(s.ClusterTypes, dc.Spec.Type) || !matchesAny(s.InfrastructureProviders, dc.Spec.CloudProvider) ||
+		!matchesAny(s.Regions, dc.Spec.Region) {
+		return false, nil
+	}
+
+	if len(s.OpenShiftVersions) > 0 {
+		matched := false
+		for _, v := range s.OpenShiftVersions {
+			if dc.Spec.OpenshiftVersion == string(v) || strings.HasPrefix(dc.Spec.OpenshiftVersion, string(v)+".") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if s.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(s.LabelSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid label selector in DiscoveryImportPolicy %s: %w", p.Name, err)
+		}
+		if !selector.Matches(labels.Set(dc.GetLabels())) {
+			return false, nil
+		}
+	}
+
+	if s.NamePattern != "" {
+		re, err := regexp.Compile(s.NamePattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid name pattern in DiscoveryImportPolicy %s: %w", p.Name, err)
+		}
+		if !re.MatchString(dc.Spec.DisplayName) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// GetRateLimitPeriod returns the rate limit period of the policy, or the default period if it is not set.
+func (p *DiscoveryImportPolicy) GetRateLimitPeriod() time.Duration {
+	if p.Spec.RateLimit == nil || p.Spec.RateLimit.Period == nil || p.Spec.RateLimit.Period.Duration <= 0 {
+		return DefaultImportRateLimitPeriod
+	}
+	return p.Spec.RateLimit.Period.Duration
+}
+
+// matchesAny returns true if the list is empty or contains the value, ignoring case.
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	SchemeBuilder.Register(&DiscoveryImportPolicy{}, &DiscoveryImportPolicyList{})
+}
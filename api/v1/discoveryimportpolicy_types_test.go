@@ -0,0 +1,100 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiscoveryImportPolicy_Matches(t *testing.T) {
+	dc := &DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "prod"}},
+		Spec: DiscoveredClusterSpec{
+			DisplayName:      "prod-cluster-1",
+			Type:             "ROSA",
+			CloudProvider:    "aws",
+			Region:           "us-east-1",
+			OpenshiftVersion: "4.14.3",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector ImportPolicyClusterSelector
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "Empty selector matches all clusters",
+			want: true,
+		},
+		{
+			name: "All criteria match",
+			selector: ImportPolicyClusterSelector{
+				ClusterTypes:            []string{"OCP", "ROSA"},
+				InfrastructureProviders: []string{"AWS"},
+				OpenShiftVersions:       []Semver{"4.14"},
+				Regions:                 []string{"us-east-1"},
+				LabelSelector:           &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				NamePattern:             "^prod-",
+			},
+			want: true,
+		},
+		{
+			name:     "Cluster type does not match",
+			selector: ImportPolicyClusterSelector{ClusterTypes: []string{"OCP"}},
+			want:     false,
+		},
+		{
+			name:     "Minor version prefix does not match",
+			selector: ImportPolicyClusterSelector{OpenShiftVersions: []Semver{"4.1"}},
+			want:     false,
+		},
+		{
+			name: "Labels do not match",
+			selector: ImportPolicyClusterSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			},
+			want: false,
+		},
+		{
+			name:     "Name pattern does not match",
+			selector: ImportPolicyClusterSelector{NamePattern: "^dev-"},
+			want:     false,
+		},
+		{
+			name:     "Invalid name pattern",
+			selector: ImportPolicyClusterSelector{NamePattern: "("},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &DiscoveryImportPolicy{Spec: DiscoveryImportPolicySpec{ClusterSelector: tt.selector}}
+			got, err := p.Matches(dc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Matches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
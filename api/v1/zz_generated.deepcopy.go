@@ -21,6 +21,8 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -111,6 +113,20 @@ func (in *DiscoveredClusterSpec) DeepCopyInto(out *DiscoveredClusterSpec) {
 		*out = (*in).DeepCopy()
 	}
 	out.Credential = in.Credential
+	if in.ImportCredential != nil {
+		in, out := &in.ImportCredential, &out.ImportCredential
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ManagedCluster != nil {
+		in, out := &in.ManagedCluster, &out.ManagedCluster
+		*out = new(ManagedClusterSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrialEndDate != nil {
+		in, out := &in.TrialEndDate, &out.TrialEndDate
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredClusterSpec.
@@ -133,6 +149,13 @@ func (in *DiscoveredClusterStatus) DeepCopyInto(out *DiscoveredClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReimportHistory != nil {
+		in, out := &in.ReimportHistory, &out.ReimportHistory
+		*out = make([]ReimportRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredClusterStatus.
@@ -208,6 +231,36 @@ func (in *DiscoveryConfigList) DeepCopyObject() runtime.Object {
 func (in *DiscoveryConfigSpec) DeepCopyInto(out *DiscoveryConfigSpec) {
 	*out = *in
 	in.Filters.DeepCopyInto(&out.Filters)
+	if in.TrialExpiryWindow != nil {
+		in, out := &in.TrialExpiryWindow, &out.TrialExpiryWindow
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StaleAfter != nil {
+		in, out := &in.StaleAfter, &out.StaleAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ImportCredentials != nil {
+		in, out := &in.ImportCredentials, &out.ImportCredentials
+		*out = make([]ImportCredentialMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedCluster != nil {
+		in, out := &in.ManagedCluster, &out.ManagedCluster
+		*out = new(ManagedClusterSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KlusterletAddonConfigTemplate != nil {
+		in, out := &in.KlusterletAddonConfigTemplate, &out.KlusterletAddonConfigTemplate
+		*out = new(KlusterletAddonConfigTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostedAddOns != nil {
+		in, out := &in.HostedAddOns, &out.HostedAddOns
+		*out = new(HostedAddOnSettings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryConfigSpec.
@@ -235,6 +288,113 @@ func (in *DiscoveryConfigStatus) DeepCopy() *DiscoveryConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryImportPolicy) DeepCopyInto(out *DiscoveryImportPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryImportPolicy.
+func (in *DiscoveryImportPolicy) DeepCopy() *DiscoveryImportPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryImportPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiscoveryImportPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryImportPolicyList) DeepCopyInto(out *DiscoveryImportPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DiscoveryImportPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryImportPolicyList.
+func (in *DiscoveryImportPolicyList) DeepCopy() *DiscoveryImportPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryImportPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiscoveryImportPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryImportPolicySpec) DeepCopyInto(out *DiscoveryImportPolicySpec) {
+	*out = *in
+	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(ImportRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManagedClusterLabels != nil {
+		in, out := &in.ManagedClusterLabels, &out.ManagedClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KlusterletAddonConfigTemplate != nil {
+		in, out := &in.KlusterletAddonConfigTemplate, &out.KlusterletAddonConfigTemplate
+		*out = new(KlusterletAddonConfigTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryImportPolicySpec.
+func (in *DiscoveryImportPolicySpec) DeepCopy() *DiscoveryImportPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryImportPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryImportPolicyStatus) DeepCopyInto(out *DiscoveryImportPolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryImportPolicyStatus.
+func (in *DiscoveryImportPolicyStatus) DeepCopy() *DiscoveryImportPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryImportPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Filter) DeepCopyInto(out *Filter) {
 	*out = *in
@@ -269,3 +429,200 @@ func (in *Filter) DeepCopy() *Filter {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostedAddOnSettings) DeepCopyInto(out *HostedAddOnSettings) {
+	*out = *in
+	if in.AddOns != nil {
+		in, out := &in.AddOns, &out.AddOns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostedAddOnSettings.
+func (in *HostedAddOnSettings) DeepCopy() *HostedAddOnSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(HostedAddOnSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportCredentialMapping) DeepCopyInto(out *ImportCredentialMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportCredentialMapping.
+func (in *ImportCredentialMapping) DeepCopy() *ImportCredentialMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportCredentialMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportPolicyClusterSelector) DeepCopyInto(out *ImportPolicyClusterSelector) {
+	*out = *in
+	if in.ClusterTypes != nil {
+		in, out := &in.ClusterTypes, &out.ClusterTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InfrastructureProviders != nil {
+		in, out := &in.InfrastructureProviders, &out.InfrastructureProviders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OpenShiftVersions != nil {
+		in, out := &in.OpenShiftVersions, &out.OpenShiftVersions
+		*out = make([]Semver, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportPolicyClusterSelector.
+func (in *ImportPolicyClusterSelector) DeepCopy() *ImportPolicyClusterSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportPolicyClusterSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportRateLimit) DeepCopyInto(out *ImportRateLimit) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportRateLimit.
+func (in *ImportRateLimit) DeepCopy() *ImportRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlusterletAddonConfigTemplate) DeepCopyInto(out *KlusterletAddonConfigTemplate) {
+	*out = *in
+	if in.ApplicationManager != nil {
+		in, out := &in.ApplicationManager, &out.ApplicationManager
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CertPolicyController != nil {
+		in, out := &in.CertPolicyController, &out.CertPolicyController
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PolicyController != nil {
+		in, out := &in.PolicyController, &out.PolicyController
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SearchCollector != nil {
+		in, out := &in.SearchCollector, &out.SearchCollector
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClusterLabels != nil {
+		in, out := &in.ClusterLabels, &out.ClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlusterletAddonConfigTemplate.
+func (in *KlusterletAddonConfigTemplate) DeepCopy() *KlusterletAddonConfigTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KlusterletAddonConfigTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterSettings) DeepCopyInto(out *ManagedClusterSettings) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterSettings.
+func (in *ManagedClusterSettings) DeepCopy() *ManagedClusterSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReimportRecord) DeepCopyInto(out *ReimportRecord) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReimportRecord.
+func (in *ReimportRecord) DeepCopy() *ReimportRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ReimportRecord)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,205 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	discoveryv1 "github.com/stolostron/discovery/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// discoveredClusterConversionData holds the fields of a v1 DiscoveredCluster that v1alpha1 cannot represent.
+type discoveredClusterConversionData struct {
+	Spec   discoveryv1.DiscoveredClusterSpec   `json:"spec,omitempty"`
+	Status discoveryv1.DiscoveredClusterStatus `json:"status,omitempty"`
+}
+
+// discoveryConfigConversionData holds the fields of a v1 DiscoveryConfig that v1alpha1 cannot represent.
+type discoveryConfigConversionData struct {
+	Spec discoveryv1.DiscoveryConfigSpec `json:"spec,omitempty"`
+}
+
+var _ conversion.Convertible = &DiscoveredCluster{}
+
+// ConvertTo converts the DiscoveredCluster to v1, restoring the v1 fields saved in the conversion data annotation.
+func (src *DiscoveredCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*discoveryv1.DiscoveredCluster)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	data := discoveredClusterConversionData{}
+	if err := restoreConversionData(&dst.ObjectMeta, &data); err != nil {
+		return err
+	}
+
+	dst.Spec, dst.Status = data.Spec, data.Status
+	src.Spec.convertTo(&dst.Spec)
+	return nil
+}
+
+// ConvertFrom converts the v1 DiscoveredCluster to v1alpha1, saving the v1 fields that v1alpha1 cannot represent in
+// the conversion data annotation.
+func (dst *DiscoveredCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*discoveryv1.DiscoveredCluster)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.convertFrom(src.Spec)
+	dst.Status = DiscoveredClusterStatus{}
+
+	data := discoveredClusterConversionData{Spec: *src.Spec.DeepCopy(), Status: *src.Status.DeepCopy()}
+	DiscoveredClusterSpec{}.convertTo(&data.Spec)
+	return saveConversionData(&dst.ObjectMeta, data,
+		equality.Semantic.DeepEqual(data, discoveredClusterConversionData{}))
+}
+
+// convertTo sets the fields of the v1 spec that v1alpha1 represents.
+func (s DiscoveredClusterSpec) convertTo(dst *discoveryv1.DiscoveredClusterSpec) {
+	dst.Name = s.Name
+	dst.DisplayName = s.DisplayName
+	dst.Console = s.Console
+	dst.APIURL = s.APIURL
+	dst.CreationTimestamp = s.CreationTimestamp.DeepCopy()
+	dst.ActivityTimestamp = s.ActivityTimestamp.DeepCopy()
+	dst.Type = s.Type
+	dst.OpenshiftVersion = s.OpenshiftVersion
+	dst.CloudProvider = s.CloudProvider
+	dst.Status = s.Status
+	dst.IsManagedCluster = s.IsManagedCluster
+	dst.Credential = s.Credential
+}
+
+// convertFrom sets the spec from the fields of the v1 spec that v1alpha1 represents.
+func (s *DiscoveredClusterSpec) convertFrom(src discoveryv1.DiscoveredClusterSpec) {
+	*s = DiscoveredClusterSpec{
+		Name:              src.Name,
+		DisplayName:       src.DisplayName,
+		Console:           src.Console,
+		APIURL:            src.APIURL,
+		CreationTimestamp: src.CreationTimestamp.DeepCopy(),
+		ActivityTimestamp: src.ActivityTimestamp.DeepCopy(),
+		Type:              src.Type,
+		OpenshiftVersion:  src.OpenshiftVersion,
+		CloudProvider:     src.CloudProvider,
+		Status:            src.Status,
+		IsManagedCluster:  src.IsManagedCluster,
+		Credential:        src.Credential,
+	}
+}
+
+var _ conversion.Convertible = &DiscoveryConfig{}
+
+// ConvertTo converts the DiscoveryConfig to v1, restoring the v1 fields saved in the conversion data annotation.
+func (src *DiscoveryConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*discoveryv1.DiscoveryConfig)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	data := discoveryConfigConversionData{}
+	if err := restoreConversionData(&dst.ObjectMeta, &data); err != nil {
+		return err
+	}
+
+	dst.Spec = data.Spec
+	src.Spec.convertTo(&dst.Spec)
+	dst.Status = discoveryv1.DiscoveryConfigStatus{}
+	return nil
+}
+
+// ConvertFrom converts the v1 DiscoveryConfig to v1alpha1, saving the v1 fields that v1alpha1 cannot represent in the
+// conversion data annotation.
+func (dst *DiscoveryConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*discoveryv1.DiscoveryConfig)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.convertFrom(src.Spec)
+	dst.Status = DiscoveryConfigStatus{}
+
+	data := discoveryConfigConversionData{Spec: *src.Spec.DeepCopy()}
+	DiscoveryConfigSpec{}.convertTo(&data.Spec)
+	return saveConversionData(&dst.ObjectMeta, data,
+		equality.Semantic.DeepEqual(data, discoveryConfigConversionData{}))
+}
+
+// convertTo sets the fields of the v1 spec that v1alpha1 represents.
+func (s DiscoveryConfigSpec) convertTo(dst *discoveryv1.DiscoveryConfigSpec) {
+	dst.Credential = s.Credential
+	dst.Filters.LastActive = s.Filters.LastActive
+
+	dst.Filters.OpenShiftVersions = nil
+	for _, v := range s.Filters.OpenShiftVersions {
+		dst.Filters.OpenShiftVersions = append(dst.Filters.OpenShiftVersions, discoveryv1.Semver(v))
+	}
+}
+
+// convertFrom sets the spec from the fields of the v1 spec that v1alpha1 represents.
+func (s *DiscoveryConfigSpec) convertFrom(src discoveryv1.DiscoveryConfigSpec) {
+	*s = DiscoveryConfigSpec{
+		Credential: src.Credential,
+		Filters:    Filter{LastActive: src.Filters.LastActive},
+	}
+	for _, v := range src.Filters.OpenShiftVersions {
+		s.Filters.OpenShiftVersions = append(s.Filters.OpenShiftVersions, Semver(v))
+	}
+}
+
+// saveConversionData stores the data in the conversion data annotation of the object, or removes the annotation if
+// there is no data to preserve.
+func saveConversionData(obj metav1.Object, data interface{}, empty bool) error {
+	annotations := obj.GetAnnotations()
+	delete(annotations, discoveryv1.ConversionDataAnnotation)
+
+	if !empty {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal conversion data: %w", err)
+		}
+
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[discoveryv1.ConversionDataAnnotation] = string(raw)
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// restoreConversionData reads the conversion data annotation of the object into data and removes the annotation. The
+// data is left unchanged when the object has no conversion data.
+func restoreConversionData(obj metav1.Object, data interface{}) error {
+	annotations := obj.GetAnnotations()
+	raw, found := annotations[discoveryv1.ConversionDataAnnotation]
+	if !found {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), data); err != nil {
+		return fmt.Errorf("failed to unmarshal conversion data: %w", err)
+	}
+
+	delete(annotations, discoveryv1.ConversionDataAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
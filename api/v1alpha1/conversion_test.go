@@ -0,0 +1,188 @@
+// Copyright Contributors to the Open Cluster Management project
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	discoveryv1 "github.com/stolostron/discovery/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/randfill"
+)
+
+// fuzzSeeds is the number of seeds added to the corpus of the round-trip fuzz tests, which go test runs without -fuzz.
+const fuzzSeeds = 200
+
+// newFiller returns a filler for the seed that generates values that survive a JSON round trip. The TypeMeta of the
+// filled objects must be cleared, since it is set by the conversion webhook rather than by the conversion functions.
+func newFiller(seed int64) *randfill.Filler {
+	return randfill.NewWithSeed(seed).NilChance(0.2).NumElements(0, 3).Funcs(
+		func(d *metav1.Duration, c randfill.Continue) {
+			d.Duration = time.Duration(c.Int63n(int64(1000 * time.Hour)))
+		},
+	)
+}
+
+func FuzzDiscoveredClusterHubRoundTrip(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		want := &discoveryv1.DiscoveredCluster{}
+		newFiller(seed).Fill(want)
+		want.TypeMeta = metav1.TypeMeta{}
+
+		spoke := &DiscoveredCluster{}
+		if err := spoke.ConvertFrom(want.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		got := &discoveryv1.DiscoveredCluster{}
+		if err := spoke.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(want, got) {
+			t.Errorf("round trip through v1alpha1 changed the DiscoveredCluster:\n%s", diff.Diff(want, got))
+		}
+	})
+}
+
+func FuzzDiscoveredClusterSpokeRoundTrip(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		want := &DiscoveredCluster{}
+		newFiller(seed).Fill(want)
+		want.TypeMeta = metav1.TypeMeta{}
+
+		hub := &discoveryv1.DiscoveredCluster{}
+		if err := want.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		got := &DiscoveredCluster{}
+		if err := got.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(want, got) {
+			t.Errorf("round trip through v1 changed the DiscoveredCluster:\n%s", diff.Diff(want, got))
+		}
+	})
+}
+
+func FuzzDiscoveryConfigHubRoundTrip(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		want := &discoveryv1.DiscoveryConfig{}
+		newFiller(seed).Fill(want)
+		want.TypeMeta = metav1.TypeMeta{}
+
+		spoke := &DiscoveryConfig{}
+		if err := spoke.ConvertFrom(want.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		got := &discoveryv1.DiscoveryConfig{}
+		if err := spoke.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(want, got) {
+			t.Errorf("round trip through v1alpha1 changed the DiscoveryConfig:\n%s", diff.Diff(want, got))
+		}
+	})
+}
+
+func FuzzDiscoveryConfigSpokeRoundTrip(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		want := &DiscoveryConfig{}
+		newFiller(seed).Fill(want)
+		want.TypeMeta = metav1.TypeMeta{}
+
+		hub := &discoveryv1.DiscoveryConfig{}
+		if err := want.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		got := &DiscoveryConfig{}
+		if err := got.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(want, got) {
+			t.Errorf("round trip through v1 changed the DiscoveryConfig:\n%s", diff.Diff(want, got))
+		}
+	})
+}
+
+func TestDiscoveryConfig_ConvertFrom(t *testing.T) {
+	hub := &discoveryv1.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery", Namespace: "foo"},
+		Spec: discoveryv1.DiscoveryConfigSpec{
+			Credential: "ocm-token",
+			Filters: discoveryv1.Filter{
+				LastActive:        7,
+				OpenShiftVersions: []discoveryv1.Semver{"4.16"},
+				Regions:           []string{"us-east-1"},
+			},
+		},
+	}
+
+	spoke := &DiscoveryConfig{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+
+	wantSpec := DiscoveryConfigSpec{
+		Credential: "ocm-token",
+		Filters:    Filter{LastActive: 7, OpenShiftVersions: []Semver{"4.16"}},
+	}
+	if !equality.Semantic.DeepEqual(spoke.Spec, wantSpec) {
+		t.Errorf("spec = %+v, want %+v", spoke.Spec, wantSpec)
+	}
+
+	wantData := `{"spec":{"credential":"","filters":{"regions":["us-east-1"]}}}`
+	if got := spoke.GetAnnotations()[discoveryv1.ConversionDataAnnotation]; got != wantData {
+		t.Errorf("conversion data = %s, want %s", got, wantData)
+	}
+
+	// A v1alpha1 client edits the fields it knows about, which take precedence over the conversion data.
+	spoke.Spec.Filters.LastActive = 30
+	got := &discoveryv1.DiscoveryConfig{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got.Spec.Filters.LastActive != 30 || len(got.Spec.Filters.Regions) != 1 || len(got.GetAnnotations()) != 0 {
+		t.Errorf("ConvertTo() = %+v, want lastActive 30, the regions restored and no annotations", got)
+	}
+}
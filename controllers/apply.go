@@ -0,0 +1,162 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// syncerFieldOwner is the field manager that owns the DiscoveredCluster fields derived from OCM.
+	syncerFieldOwner = "discovery-syncer"
+
+	// managedClusterFieldOwner is the field manager that owns the managed status of a DiscoveredCluster.
+	managedClusterFieldOwner = "discovery-managedcluster"
+
+	// managedLabel is the label set on DiscoveredClusters that have been imported as ManagedClusters.
+	managedLabel = "isManagedCluster"
+)
+
+/*
+applySourceFields server-side applies the OCM-derived spec fields of the DiscoveredCluster as the discovery syncer,
+creating the DiscoveredCluster if it does not exist yet, and updates dc with the result. The DiscoveryConfig is set as
+its controller. User intent
+(importAsManagedCluster, importApproved, importCredential, managedCluster) and managed status (isManagedCluster) are
+left out of the apply configuration so that they remain owned by the users and the ManagedCluster controller
+respectively.
+*/
+func applySourceFields(ctx context.Context, c client.Client, config *discovery.DiscoveryConfig,
+	dc *discovery.DiscoveredCluster) error {
+	spec, err := sourceFields(*dc)
+	if err != nil {
+		return err
+	}
+
+	obj := newApplyConfiguration(*dc)
+	obj.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(config, discovery.GroupVersion.WithKind("DiscoveryConfig")),
+	})
+	obj.Object["spec"] = spec
+
+	if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(syncerFieldOwner), client.ForceOwnership); err != nil {
+		return errors.Wrapf(err, "error applying DiscoveredCluster %s", dc.Name)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, dc); err != nil {
+		return errors.Wrapf(err, "error converting DiscoveredCluster %s", dc.Name)
+	}
+	return nil
+}
+
+/*
+migrateSourceFields prepares a DiscoveredCluster written before its OCM-derived fields were server-side applied.
+Fields that the syncer owns through Update operations are moved to its Apply entry, so that leaving them out of the
+apply configuration removes them. Fields that OCM no longer reports may still be owned by other legacy managers, which
+would keep them on the object, so they are cleared explicitly.
+*/
+func migrateSourceFields(ctx context.Context, c client.Client, current, dc discovery.DiscoveredCluster) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(&current, sets.New(syncerFieldOwner), syncerFieldOwner)
+	if err != nil {
+		return errors.Wrapf(err, "error upgrading managed fields of DiscoveredCluster %s", dc.Name)
+	}
+	if patch != nil {
+		if err := c.Patch(ctx, &current, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+			return errors.Wrapf(err, "error upgrading managed fields of DiscoveredCluster %s", dc.Name)
+		}
+	}
+
+	currentFields, err := sourceFields(current)
+	if err != nil {
+		return err
+	}
+	desiredFields, err := sourceFields(dc)
+	if err != nil {
+		return err
+	}
+
+	removed := map[string]interface{}{}
+	for field := range currentFields {
+		if _, found := desiredFields[field]; !found {
+			removed[field] = nil
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"spec": removed})
+	if err != nil {
+		return errors.Wrapf(err, "error clearing removed fields of DiscoveredCluster %s", dc.Name)
+	}
+	if err := c.Patch(ctx, &current, client.RawPatch(types.MergePatchType, data),
+		client.FieldOwner(syncerFieldOwner)); err != nil {
+		return errors.Wrapf(err, "error clearing removed fields of DiscoveredCluster %s", dc.Name)
+	}
+	return nil
+}
+
+// sourceFields returns the spec of the DiscoveredCluster without the fields that are not derived from OCM.
+func sourceFields(dc discovery.DiscoveredCluster) (map[string]interface{}, error) {
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dc.Spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error converting DiscoveredCluster %s", dc.Name)
+	}
+	delete(spec, "importApproved")
+	delete(spec, "importAsManagedCluster")
+	delete(spec, "importCredential")
+	delete(spec, "managedCluster")
+	delete(spec, "isManagedCluster")
+	return spec, nil
+}
+
+/*
+applyManagedStatus server-side applies the managed label and spec.isManagedCluster of the DiscoveredCluster as the
+ManagedCluster controller. The label is dropped from the apply configuration when the cluster is not managed, which
+removes it from the object. Labels written before field ownership was introduced are not owned by the apply manager,
+so they are removed explicitly.
+*/
+func applyManagedStatus(ctx context.Context, c client.Client, dc discovery.DiscoveredCluster, managed bool) error {
+	obj := newApplyConfiguration(dc)
+	if managed {
+		obj.SetLabels(map[string]string{managedLabel: "true"})
+	}
+	obj.Object["spec"] = map[string]interface{}{"isManagedCluster": managed}
+
+	if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(managedClusterFieldOwner),
+		client.ForceOwnership); err != nil {
+		return errors.Wrapf(err, "error applying managed status to DiscoveredCluster %s", dc.Name)
+	}
+
+	if _, found := obj.GetLabels()[managedLabel]; !managed && found {
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"labels":{"`+managedLabel+`":null}}}`))
+		if err := c.Patch(ctx, obj, patch, client.FieldOwner(managedClusterFieldOwner)); err != nil {
+			return errors.Wrapf(err, "error removing managed label from DiscoveredCluster %s", dc.Name)
+		}
+	}
+	return nil
+}
+
+// managedStatusChanged reports whether the managed label or spec.isManagedCluster of dc differ from managed.
+func managedStatusChanged(dc discovery.DiscoveredCluster, managed bool) bool {
+	return dc.Spec.IsManagedCluster != managed || (dc.Labels[managedLabel] == "true") != managed
+}
+
+// newApplyConfiguration returns an empty apply configuration identifying the given DiscoveredCluster.
+func newApplyConfiguration(dc discovery.DiscoveredCluster) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(discovery.GroupVersion.WithKind("DiscoveredCluster"))
+	obj.SetName(dc.Name)
+	obj.SetNamespace(dc.Namespace)
+	return obj
+}
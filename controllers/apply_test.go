@@ -0,0 +1,300 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	discovery "github.com/stolostron/discovery/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+/*
+newApplyClient returns a fake client that tracks managed fields and supports server-side apply. The controller-runtime
+fake client rejects apply patches, so they are passed to the field managed object tracker directly.
+*/
+func newApplyClient(objs ...client.Object) client.Client {
+	tracker := clienttesting.NewFieldManagedObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder(),
+		managedfields.NewDeducedTypeConverter())
+
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjectTracker(tracker).WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				if patch.Type() != types.ApplyPatchType {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+
+				data, err := patch.Data(obj)
+				if err != nil {
+					return err
+				}
+				applyConfiguration := &unstructured.Unstructured{}
+				if err := json.Unmarshal(data, &applyConfiguration.Object); err != nil {
+					return err
+				}
+
+				gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+				if err != nil {
+					return err
+				}
+				gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+				patchOptions := &client.PatchOptions{}
+				patchOptions.ApplyOptions(opts)
+				if err := tracker.Apply(gvr, applyConfiguration, obj.GetNamespace(),
+					*patchOptions.AsPatchOptions()); err != nil {
+					return err
+				}
+				return c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+			},
+		}).Build()
+}
+
+// getManagedFields returns the fields owned by the given manager and operation, or nil if it owns none.
+func getManagedFields(obj client.Object, manager string, operation metav1.ManagedFieldsOperationType) map[string]any {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != manager || entry.Operation != operation || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]any{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil
+		}
+		return fields
+	}
+	return nil
+}
+
+// ownsField reports whether the given manager and operation own the field at the given path.
+func ownsField(obj client.Object, manager string, operation metav1.ManagedFieldsOperationType,
+	path ...string) bool {
+	fields := getManagedFields(obj, manager, operation)
+	for _, key := range path {
+		next, ok := fields[key].(map[string]any)
+		if !ok {
+			return false
+		}
+		fields = next
+	}
+	return fields != nil
+}
+
+func Test_applySourceFields(t *testing.T) {
+	registerScheme()
+	trialEnd := metav1.NewTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	config := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "ns", UID: "config-uid"},
+	}
+	desired := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "ns"},
+		Spec: discovery.DiscoveredClusterSpec{
+			Name:         "c1",
+			DisplayName:  "c1",
+			Console:      "https://console.c1.example.com",
+			Status:       discovery.SubscriptionStatusActive,
+			TrialEndDate: &trialEnd,
+			Type:         "OCP",
+		},
+	}
+
+	c := newApplyClient()
+	dc := desired.DeepCopy()
+	if err := applySourceFields(context.TODO(), c, config, dc); err != nil {
+		t.Fatalf("applySourceFields() error = %v", err)
+	}
+	if ref := metav1.GetControllerOf(dc); ref == nil || ref.UID != config.UID {
+		t.Errorf("applySourceFields() controller = %v, want DiscoveryConfig %s", ref, config.UID)
+	}
+
+	t.Run("should own the OCM fields through an Apply entry", func(t *testing.T) {
+		for _, field := range []string{"f:console", "f:displayName", "f:status", "f:trialEndDate"} {
+			if !ownsField(dc, syncerFieldOwner, metav1.ManagedFieldsOperationApply, "f:spec", field) {
+				t.Errorf("%s does not own spec %s through an Apply entry", syncerFieldOwner, field)
+			}
+		}
+		if getManagedFields(dc, syncerFieldOwner, metav1.ManagedFieldsOperationUpdate) != nil {
+			t.Errorf("%s owns fields through an Update entry", syncerFieldOwner)
+		}
+	})
+
+	t.Run("should preserve user intent and managed status across OCM syncs", func(t *testing.T) {
+		intent := dc.DeepCopy()
+		intent.Spec.ImportAsManagedCluster = true
+		intent.Spec.ImportApproved = true
+		if err := c.Update(context.TODO(), intent, client.FieldOwner("user")); err != nil {
+			t.Fatalf("failed to update DiscoveredCluster: %v", err)
+		}
+		if err := applyManagedStatus(context.TODO(), c, *intent, true); err != nil {
+			t.Fatalf("applyManagedStatus() error = %v", err)
+		}
+
+		synced := desired.DeepCopy()
+		synced.Spec.OpenshiftVersion = "4.17.0"
+		if err := applySourceFields(context.TODO(), c, config, synced); err != nil {
+			t.Fatalf("applySourceFields() error = %v", err)
+		}
+
+		if !synced.Spec.ImportAsManagedCluster || !synced.Spec.ImportApproved {
+			t.Errorf("OCM sync reset user intent: importAsManagedCluster = %v, importApproved = %v",
+				synced.Spec.ImportAsManagedCluster, synced.Spec.ImportApproved)
+		}
+		if !synced.Spec.IsManagedCluster || synced.Labels[managedLabel] != "true" {
+			t.Errorf("OCM sync reset the managed status")
+		}
+		if synced.Spec.OpenshiftVersion != "4.17.0" {
+			t.Errorf("OCM sync did not update the OpenShift version, got %q", synced.Spec.OpenshiftVersion)
+		}
+		if !ownsField(synced, "user", metav1.ManagedFieldsOperationUpdate, "f:spec", "f:importAsManagedCluster") {
+			t.Errorf("user does not own spec.importAsManagedCluster")
+		}
+		if !ownsField(synced, managedClusterFieldOwner, metav1.ManagedFieldsOperationApply, "f:spec",
+			"f:isManagedCluster") {
+			t.Errorf("%s does not own spec.isManagedCluster", managedClusterFieldOwner)
+		}
+	})
+
+	t.Run("should remove fields that OCM no longer reports", func(t *testing.T) {
+		synced := desired.DeepCopy()
+		synced.Spec.Console = ""
+		synced.Spec.TrialEndDate = nil
+		if err := applySourceFields(context.TODO(), c, config, synced); err != nil {
+			t.Fatalf("applySourceFields() error = %v", err)
+		}
+		if synced.Spec.Console != "" || synced.Spec.TrialEndDate != nil {
+			t.Errorf("OCM sync kept removed fields: console = %q, trialEndDate = %v", synced.Spec.Console,
+				synced.Spec.TrialEndDate)
+		}
+	})
+}
+
+func Test_migrateSourceFields(t *testing.T) {
+	registerScheme()
+	trialEnd := metav1.NewTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	config := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "ns", UID: "config-uid"},
+	}
+	legacy := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "ns"},
+		Spec: discovery.DiscoveredClusterSpec{
+			Name:         "c1",
+			DisplayName:  "c1",
+			Console:      "https://console.c1.example.com",
+			Status:       discovery.SubscriptionStatusActive,
+			TrialEndDate: &trialEnd,
+			Type:         "OCP",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		manager string
+	}{
+		{
+			name:    "should migrate fields created by the syncer",
+			manager: syncerFieldOwner,
+		},
+		{
+			name:    "should clear removed fields owned by a legacy manager",
+			manager: "manager",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newApplyClient()
+			current := legacy.DeepCopy()
+			if err := c.Create(context.TODO(), current, client.FieldOwner(tt.manager)); err != nil {
+				t.Fatalf("failed to create DiscoveredCluster: %v", err)
+			}
+
+			synced := legacy.DeepCopy()
+			synced.Spec.Console = ""
+			synced.Spec.TrialEndDate = nil
+			if err := migrateSourceFields(context.TODO(), c, *current, *synced); err != nil {
+				t.Fatalf("migrateSourceFields() error = %v", err)
+			}
+			if err := applySourceFields(context.TODO(), c, config, synced); err != nil {
+				t.Fatalf("applySourceFields() error = %v", err)
+			}
+
+			if synced.Spec.Console != "" || synced.Spec.TrialEndDate != nil {
+				t.Errorf("OCM sync kept removed fields: console = %q, trialEndDate = %v", synced.Spec.Console,
+					synced.Spec.TrialEndDate)
+			}
+			want := legacy.DeepCopy()
+			want.Spec.Console = ""
+			want.Spec.TrialEndDate = nil
+			if !want.SourceEqual(*synced) {
+				t.Errorf("SourceEqual() does not converge after the OCM sync")
+			}
+			if getManagedFields(synced, syncerFieldOwner, metav1.ManagedFieldsOperationUpdate) != nil {
+				t.Errorf("%s still owns fields through an Update entry", syncerFieldOwner)
+			}
+		})
+	}
+}
+
+func Test_applyManagedStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+	}{
+		{
+			name: "should remove the managed status it applied",
+		},
+		{
+			name:   "should remove a managed label written before field ownership",
+			labels: map[string]string{managedLabel: "true"},
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newApplyClient()
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "ns", Labels: tt.labels},
+				Spec:       discovery.DiscoveredClusterSpec{Name: "c1", DisplayName: "c1", Type: "OCP"},
+			}
+			if err := c.Create(context.TODO(), dc, client.FieldOwner("manager")); err != nil {
+				t.Fatalf("failed to create DiscoveredCluster: %v", err)
+			}
+
+			if err := applyManagedStatus(context.TODO(), c, *dc, true); err != nil {
+				t.Fatalf("applyManagedStatus() error = %v", err)
+			}
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(dc), dc); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if !ownsField(dc, managedClusterFieldOwner, metav1.ManagedFieldsOperationApply, "f:metadata", "f:labels",
+				"f:"+managedLabel) {
+				t.Errorf("%s does not own the managed label", managedClusterFieldOwner)
+			}
+
+			if err := applyManagedStatus(context.TODO(), c, *dc, false); err != nil {
+				t.Fatalf("applyManagedStatus() error = %v", err)
+			}
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(dc), dc); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if managedStatusChanged(*dc, false) {
+				t.Errorf("managed status was not removed: label = %q, isManagedCluster = %v", dc.Labels[managedLabel],
+					dc.Spec.IsManagedCluster)
+			}
+		})
+	}
+}
@@ -25,45 +25,81 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	discovery "github.com/stolostron/discovery/api/v1"
+	"github.com/stolostron/discovery/pkg/ocm/auth"
+	"github.com/stolostron/discovery/pkg/platform"
 	utils "github.com/stolostron/discovery/util"
 	recon "github.com/stolostron/discovery/util/reconciler"
 	agentv1 "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
 	clusterapiv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	clusterapiv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // DiscoveredClusterReconciler reconciles a DiscoveredCluster object
 type DiscoveredClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ImportQueue limits the imports that run at the same time. Imports are not limited when it is nil.
+	ImportQueue *ImportQueue
+
+	// Capabilities are the optional APIs served by the hub. Every API is assumed to be served when it is nil.
+	Capabilities *platform.Capabilities
+
+	// appliedHostedAddOns are the hosted addon settings last applied to the ClusterManagementAddOns. It is guarded by
+	// hostedAddOnsMu.
+	hostedAddOnsMu      sync.Mutex
+	appliedHostedAddOns *discovery.HostedAddOnSettings
+
+	// policyImports are the imports triggered by each DiscoveryImportPolicy that the cache may not reflect yet.
+	policyImports importPolicyImports
 }
 
+const (
+	// managedClusterConditionImportSucceeded is the ManagedCluster condition set by the import controller.
+	managedClusterConditionImportSucceeded = "ManagedClusterImportSucceeded"
+
+	// managedClusterReasonImportFailed is the reason of the import condition when the import controller gave up.
+	managedClusterReasonImportFailed = "ManagedClusterImportFailed"
+)
+
 const (
 	AddOnDeploymentConfigName = "addon-ns-config"
 	DefaultName               = "default"
 )
 
 // +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveredclusters,verbs=create;delete;deletecollection;get;list;patch;update;watch
-// +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveredclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveredclusters/status,verbs=get;patch;update
 // +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveredclusters/finalizers,verbs=get;patch;update
+// +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveryimportpolicies,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
-// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=create;get;list;update;watch
-// +kubebuilder:rbac:groups=config.open-cluster-management.io,resources=klusterletconfigs,verbs=create;get;list;patch;update;
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,resourceNames=discoveredclusters.discovery.open-cluster-management.io;discoveryconfigs.discovery.open-cluster-management.io,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces;secrets,verbs=delete
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=delete
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=create;get;list;update;watch
+// +kubebuilder:rbac:groups=config.open-cluster-management.io,resources=klusterletconfigs,verbs=create;get;list;patch;update;watch
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=addondeploymentconfigs;clustermanagementaddons,verbs=create;get;list;update;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -89,6 +125,7 @@ func (r *DiscoveredClusterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			// Return and don't requeue.
 			logf.Info("DiscoveredCluster resource not found. Ignoring since objects must be deleted",
 				"Name", req.Name, "Namespace", req.Namespace)
+			r.releaseImport(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -99,45 +136,165 @@ func (r *DiscoveredClusterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		dc.Annotations = make(map[string]string)
 	}
 
+	// Remove the import artifacts once the DiscoveredCluster is deleted.
+	if !dc.GetDeletionTimestamp().IsZero() {
+		r.releaseImport(req.NamespacedName)
+		if err := r.finalizeImport(ctx, dc); err != nil {
+			logf.Error(err, "Failed to clean up import artifacts", "Name", dc.Name)
+			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	config := r.getDiscoveryConfig(ctx, dc.Namespace)
+	strategy := discovery.EffectiveImportStrategy(dc, config)
+
+	// Restart the import of clusters whose ManagedCluster was deleted once a reimport is requested.
+	reimportWait, err := r.handleReimport(ctx, dc, strategy, time.Now())
+	if err != nil {
+		logf.Error(err, "Failed to reimport DiscoveredCluster", "Name", dc.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+
+	// Set the import intent of clusters that are selected by a DiscoveryImportPolicy.
+	policyWait, err := r.applyImportPolicies(ctx, dc, strategy, time.Now())
+	if err != nil {
+		logf.Error(err, "Failed to apply DiscoveryImportPolicies", "Name", dc.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+
+	switch {
+	case dc.Spec.IsManagedCluster:
+		// Once the cluster is managed, the import artifacts belong to the ManagedCluster and are kept.
+		err = r.removeImportCleanUpFinalizer(ctx, dc)
+	case !dc.Spec.ImportAsManagedCluster || strategy == discovery.ImportStrategyDisabled:
+		// The import was abandoned or disabled before the cluster was managed.
+		err = r.finalizeImport(ctx, dc)
+	}
+	if err != nil {
+		logf.Error(err, "Failed to finalize import", "Name", dc.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+
 	/*
-		If the discovered cluster has an Automatic import strategy, we need to ensure that the required resources
-		are available. Otherwise, we will ignore that cluster.
+		If the discovered cluster has an Automatic or Manual import strategy, we need to ensure that the required
+		resources are available. Otherwise, we will ignore that cluster.
 	*/
-	if !dc.Spec.IsManagedCluster && dc.Spec.ImportAsManagedCluster {
-		if !utils.IsAnnotationTrue(dc, utils.AnnotationPreviouslyAutoImported) {
-			switch dc.Spec.Type {
-			case "MultiClusterEngineHCP":
-				if res, err := r.EnsureMultiClusterEngineHCP(ctx, dc); err != nil {
-					return res, err
+	var queueWait time.Duration
+	if !dc.Spec.IsManagedCluster && dc.Spec.ImportAsManagedCluster && strategy != discovery.ImportStrategyDisabled {
+		if !r.Capabilities.Has(platform.ManagedCluster) {
+			logf.Info("ManagedCluster API is not served by the hub. Skipping automatic import.",
+				"Name", dc.Spec.DisplayName)
+			r.releaseImport(req.NamespacedName)
+		} else if !utils.IsAnnotationTrue(dc, utils.AnnotationPreviouslyAutoImported) {
+			if discovery.IsSupportedClusterType(dc.Spec.Type) {
+				if err := r.addImportCleanUpFinalizer(ctx, dc); err != nil {
+					logf.Error(err, "Failed to add import cleanup finalizer", "Name", dc.Name)
+					return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
 				}
+			}
 
-			case "ROSA":
-				if res, err := r.EnsureROSA(ctx, dc); err != nil {
-					return res, err
-				}
+			if err := r.ensureManagedClusterName(ctx, dc, config); err != nil {
+				logf.Error(err, "Failed to resolve ManagedCluster name", "Name", dc.Name)
+				r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonImportFailed,
+					"Automatic import failed: %v", err)
+				return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+			}
 
-			default:
-				logf.Info("Unknown cluster type. Skipping automatic import.", "Name", dc.Spec.DisplayName,
-					"Type", dc.Spec.Type)
+			// Wait for a free slot in the import queue before the import resources are created.
+			admitted, wait := r.admitImport(ctx, dc, strategy, time.Now())
+			queueWait = wait
+			if admitted {
+				switch dc.Spec.Type {
+				case "MultiClusterEngineHCP":
+					if res, err := r.EnsureMultiClusterEngineHCP(ctx, dc); err != nil {
+						r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonImportFailed,
+							"Automatic import failed: %v", err)
+						return res, err
+					}
+
+				case "ROSA":
+					if res, err := r.EnsureROSA(ctx, dc); err != nil {
+						r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonImportFailed,
+							"Automatic import failed: %v", err)
+						return res, err
+					}
+
+				default:
+					if !discovery.IsCredentialImportClusterType(dc.Spec.Type) {
+						logf.Info("Unknown cluster type. Skipping automatic import.", "Name", dc.Spec.DisplayName,
+							"Type", dc.Spec.Type)
+						break
+					}
+
+					if res, err := r.EnsureCredentialImport(ctx, dc); err != nil {
+						r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonImportFailed,
+							"Automatic import failed: %v", err)
+						return res, err
+					}
+				}
 			}
 		} else {
 			logf.Info(
 				fmt.Sprintf("Skipped automatic import for DiscoveredCluster due to existing '%v' annotation",
 					utils.AnnotationPreviouslyAutoImported), "Name", dc.Spec.DisplayName)
+			r.releaseImport(req.NamespacedName)
+		}
+	} else {
+		r.releaseImport(req.NamespacedName)
+	}
+
+	// Keep the hosted addons of imported MultiClusterEngineHCP clusters in sync with the DiscoveryConfig.
+	if dc.Spec.IsManagedCluster && dc.Spec.ImportAsManagedCluster && dc.Spec.Type == "MultiClusterEngineHCP" {
+		if res, err := r.ensureHostedAddOns(ctx, *dc); err != nil {
+			return res, err
+		}
+	}
+
+	// Keep the KlusterletAddonConfigs created by the operator in sync with their template.
+	if dc.Spec.ImportAsManagedCluster || dc.Spec.IsManagedCluster {
+		if err := r.syncKlusterletAddonConfig(ctx, *dc); err != nil {
+			logf.Error(err, "Failed to sync KlusterletAddonConfig", "Name", dc.Spec.DisplayName)
+			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
 		}
 	}
 
 	// Update status conditions
-	if err := r.updateStatus(ctx, dc); err != nil {
+	if err := r.updateStatus(ctx, dc, config); err != nil {
 		logf.Error(err, "Failed to update DiscoveredCluster status", "Name", dc.Name)
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: recon.ShortRefreshInterval}, nil
+	requeueAfter := statusRefreshInterval(dc, config, time.Now())
+	for _, wait := range []time.Duration{policyWait, reimportWait, queueWait} {
+		if wait > 0 && wait < requeueAfter {
+			requeueAfter = wait
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+/*
+getDiscoveryConfig returns the DiscoveryConfig that discovered clusters in the given namespace, or nil if it cannot be
+found. Settings that are read from the DiscoveryConfig fall back to their defaults when it is nil.
+*/
+func (r *DiscoveredClusterReconciler) getDiscoveryConfig(ctx context.Context,
+	namespace string) *discovery.DiscoveryConfig {
+	config := &discovery.DiscoveryConfig{}
+	nn := types.NamespacedName{Name: discovery.DefaultDiscoveryConfigName, Namespace: namespace}
+
+	if err := r.Get(ctx, nn, config); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logf.Error(err, "failed to get DiscoveryConfig", "Name", nn.Name, "Namespace", nn.Namespace)
+		}
+		return nil
+	}
+	return config
 }
 
 // updateStatus updates the status conditions for a DiscoveredCluster
-func (r *DiscoveredClusterReconciler) updateStatus(ctx context.Context, dc *discovery.DiscoveredCluster) error {
+func (r *DiscoveredClusterReconciler) updateStatus(ctx context.Context, dc *discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig) error {
 	// Get fresh copy to avoid conflicts
 	fresh := &discovery.DiscoveredCluster{}
 	if err := r.Get(ctx, types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace}, fresh); err != nil {
@@ -149,7 +306,8 @@ func (r *DiscoveredClusterReconciler) updateStatus(ctx context.Context, dc *disc
 	}
 
 	// Build new conditions based on fresh resource
-	newConditions := r.buildStatusConditions(ctx, fresh)
+	newConditions := r.buildStatusConditions(ctx, fresh, config)
+	strategy := discovery.EffectiveImportStrategy(fresh, config)
 
 	// Preserve LastTransitionTime for conditions where Status hasn't changed
 	for i := range newConditions {
@@ -174,12 +332,16 @@ func (r *DiscoveredClusterReconciler) updateStatus(ctx context.Context, dc *disc
 		}
 	}
 
-	if !conditionsChanged {
+	position := r.getImportQueuePosition(fresh)
+	if !conditionsChanged && fresh.Status.ImportStrategy == strategy && fresh.Status.ImportQueuePosition == position {
 		return nil
 	}
 
 	// Update conditions
+	wasAvailable := isConditionTrue(fresh.Status.Conditions, discovery.ConditionAvailable)
 	fresh.Status.Conditions = newConditions
+	fresh.Status.ImportStrategy = strategy
+	fresh.Status.ImportQueuePosition = position
 
 	// Update status subresource
 	if err := r.Status().Update(ctx, fresh); err != nil {
@@ -190,41 +352,43 @@ func (r *DiscoveredClusterReconciler) updateStatus(ctx context.Context, dc *disc
 		return errors.Wrap(err, "failed to update DiscoveredCluster status")
 	}
 
+	if wasAvailable && !isConditionTrue(newConditions, discovery.ConditionAvailable) {
+		r.recordUnavailableEvent(fresh, findCondition(newConditions, discovery.ConditionAvailable))
+	}
 	return nil
 }
 
+/*
+recordUnavailableEvent records why a DiscoveredCluster that was available no longer is. A Stale event is only recorded
+when the cluster stopped sending telemetry, and an Unavailable event with the reason of the Available condition is
+recorded otherwise, such as when the cluster was disconnected or removed from OCM.
+*/
+func (r *DiscoveredClusterReconciler) recordUnavailableEvent(dc *discovery.DiscoveredCluster,
+	available *discovery.DiscoveredClusterCondition) {
+	switch available.Reason {
+	case discovery.ReasonStaleTelemetry, discovery.ReasonActivityThresholdExceeded:
+		r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonStale, "Cluster became stale: %s", available.Message)
+	default:
+		r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonUnavailable, "Cluster is no longer available (%s): %s",
+			available.Reason, available.Message)
+	}
+}
+
 // buildStatusConditions constructs the status conditions based on the cluster state
-func (r *DiscoveredClusterReconciler) buildStatusConditions(ctx context.Context, dc *discovery.DiscoveredCluster) []discovery.DiscoveredClusterCondition {
+func (r *DiscoveredClusterReconciler) buildStatusConditions(ctx context.Context, dc *discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig) []discovery.DiscoveredClusterCondition {
 	now := metav1.Now()
 	conditions := []discovery.DiscoveredClusterCondition{}
 
-	// Available condition - based on spec.status from OCM
-	availableCondition := discovery.DiscoveredClusterCondition{
-		Type:               discovery.ConditionAvailable,
-		LastTransitionTime: now,
-		ObservedGeneration: dc.Generation,
-	}
+	// Available condition - based on the staleAfter threshold, or on spec.status from OCM
+	staleAfter := getStaleAfter(config)
+	conditions = append(conditions, buildAvailableCondition(dc, staleAfter, now.Time))
 
-	if dc.Spec.Status == "Active" {
-		availableCondition.Status = metav1.ConditionTrue
-		availableCondition.Reason = discovery.ReasonRecentTelemetry
-		if dc.Spec.ActivityTimestamp != nil {
-			availableCondition.Message = fmt.Sprintf("Cluster is active. Last telemetry: %s", dc.Spec.ActivityTimestamp.Format("2006-01-02 15:04:05 MST"))
-		} else {
-			availableCondition.Message = "Cluster is active"
-		}
-	} else {
-		availableCondition.Status = metav1.ConditionFalse
-		availableCondition.Reason = discovery.ReasonStaleTelemetry
-		if dc.Spec.ActivityTimestamp != nil {
-			availableCondition.Message = fmt.Sprintf("Cluster is stale. Last telemetry: %s", dc.Spec.ActivityTimestamp.Format("2006-01-02 15:04:05 MST"))
-		} else {
-			availableCondition.Message = fmt.Sprintf("Cluster status: %s", dc.Spec.Status)
-		}
+	// OCMAvailable condition - only reported when the Available condition may be computed from the staleAfter threshold
+	if staleAfter != nil {
+		conditions = append(conditions, buildOCMStatusCondition(dc, discovery.ConditionOCMAvailable, now.Time))
 	}
 
-	conditions = append(conditions, availableCondition)
-
 	// Managed condition - based on spec.isManagedCluster
 	managedCondition := discovery.DiscoveredClusterCondition{
 		Type:               discovery.ConditionManaged,
@@ -244,9 +408,304 @@ func (r *DiscoveredClusterReconciler) buildStatusConditions(ctx context.Context,
 
 	conditions = append(conditions, managedCondition)
 
+	// Imported condition - only reported for clusters that are automatically imported
+	if dc.Spec.ImportAsManagedCluster && !r.Capabilities.Has(platform.ManagedCluster) {
+		conditions = append(conditions, discovery.DiscoveredClusterCondition{
+			Type:               discovery.ConditionImported,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: now,
+			ObservedGeneration: dc.Generation,
+			Reason:             discovery.ReasonManagedClusterAPINotServed,
+			Message:            "ManagedCluster API is not served by the hub, so the cluster cannot be imported",
+		})
+	} else if dc.Spec.ImportAsManagedCluster {
+		mc := r.getManagedCluster(ctx, dc)
+		awaitingApproval := isAwaitingApproval(dc, discovery.EffectiveImportStrategy(dc, config))
+		importedCondition := buildImportedCondition(dc, mc, awaitingApproval, now.Time)
+
+		// The ManagedCluster is not created while the KlusterletConfig it references does not exist.
+		if mc == nil && !awaitingApproval {
+			settings := r.getManagedClusterSettings(ctx, *dc)
+			if missing, err := r.getMissingKlusterletConfig(ctx, settings); err == nil && missing != "" {
+				importedCondition.Reason = discovery.ReasonKlusterletConfigNotFound
+				importedCondition.Message = fmt.Sprintf("KlusterletConfig %s does not exist", missing)
+			}
+		}
+		conditions = append(conditions, importedCondition)
+		conditions = append(conditions, r.buildNameConflictCondition(ctx, dc, config, now.Time))
+
+		if r.ImportQueue != nil {
+			conditions = append(conditions, r.buildImportQueuedCondition(dc, now.Time))
+		}
+	}
+
+	// ExpiringSoon condition - only reported for clusters with a trial subscription
+	if dc.Spec.TrialEndDate != nil {
+		conditions = append(conditions, buildExpiringSoonCondition(dc, getTrialExpiryWindow(config), now.Time))
+	}
+
 	return conditions
 }
 
+/*
+buildAvailableCondition constructs the Available condition of a DiscoveredCluster. When a staleAfter threshold is set
+and the activity of a cluster that reports telemetry is known, availability is computed from the activity timestamp and
+the status reported by OCM is reported separately in the OCMAvailable condition. Otherwise the condition mirrors the
+status reported by OCM.
+*/
+func buildAvailableCondition(dc *discovery.DiscoveredCluster, staleAfter *time.Duration,
+	now time.Time) discovery.DiscoveredClusterCondition {
+	availableCondition := discovery.DiscoveredClusterCondition{
+		Type:               discovery.ConditionAvailable,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	if staleAfter != nil && dc.Spec.ActivityTimestamp != nil && reportsTelemetry(dc.Spec.Status) {
+		lastActive := dc.Spec.ActivityTimestamp.Format("2006-01-02 15:04:05 MST")
+		if now.Before(dc.Spec.ActivityTimestamp.Add(*staleAfter)) {
+			availableCondition.Status = metav1.ConditionTrue
+			availableCondition.Reason = discovery.ReasonActivityWithinThreshold
+			availableCondition.Message = fmt.Sprintf("Cluster was active within the last %s. Last telemetry: %s",
+				*staleAfter, lastActive)
+		} else {
+			availableCondition.Status = metav1.ConditionFalse
+			availableCondition.Reason = discovery.ReasonActivityThresholdExceeded
+			availableCondition.Message = fmt.Sprintf("Cluster has not been active for %s. Last telemetry: %s",
+				*staleAfter, lastActive)
+		}
+		return availableCondition
+	}
+
+	return buildOCMStatusCondition(dc, discovery.ConditionAvailable, now)
+}
+
+// buildOCMStatusCondition constructs a condition of the given type that mirrors the status reported by OCM.
+func buildOCMStatusCondition(dc *discovery.DiscoveredCluster, conditionType string,
+	now time.Time) discovery.DiscoveredClusterCondition {
+	availableCondition := discovery.DiscoveredClusterCondition{
+		Type:               conditionType,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	lastTelemetry := ""
+	if dc.Spec.ActivityTimestamp != nil {
+		lastTelemetry = fmt.Sprintf(". Last telemetry: %s", dc.Spec.ActivityTimestamp.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	// Clusters that never reported telemetry are discovered with includeUnreported, and their availability is unknown.
+	if dc.Spec.ActivityTimestamp == nil && isUnreportedStatus(dc.Spec.Status) {
+		availableCondition.Status = metav1.ConditionUnknown
+		availableCondition.Reason = discovery.ReasonNoTelemetry
+		availableCondition.Message = fmt.Sprintf("Cluster has never reported telemetry. OCM status: %s",
+			dc.Spec.Status)
+		return availableCondition
+	}
+
+	switch dc.Spec.Status {
+	case discovery.SubscriptionStatusActive:
+		availableCondition.Status = metav1.ConditionTrue
+		availableCondition.Reason = discovery.ReasonRecentTelemetry
+		availableCondition.Message = "Cluster is active" + lastTelemetry
+	case discovery.SubscriptionStatusStale:
+		availableCondition.Status = metav1.ConditionFalse
+		availableCondition.Reason = discovery.ReasonStaleTelemetry
+		availableCondition.Message = "Cluster is stale" + lastTelemetry
+	case discovery.SubscriptionStatusDisconnected:
+		availableCondition.Status = metav1.ConditionFalse
+		availableCondition.Reason = discovery.ReasonDisconnected
+		availableCondition.Message = "Cluster has been disconnected from OpenShift Cluster Manager" + lastTelemetry
+	case discovery.SubscriptionStatusReserved:
+		availableCondition.Status = metav1.ConditionUnknown
+		availableCondition.Reason = discovery.ReasonProvisioning
+		availableCondition.Message = "Cluster is being provisioned"
+	case discovery.SubscriptionStatusDeprovisioned:
+		availableCondition.Status = metav1.ConditionFalse
+		availableCondition.Reason = discovery.ReasonDeprovisioned
+		availableCondition.Message = "Cluster has been deprovisioned" + lastTelemetry
+	case discovery.SubscriptionStatusArchived:
+		availableCondition.Status = metav1.ConditionFalse
+		availableCondition.Reason = discovery.ReasonArchived
+		availableCondition.Message = "Cluster has been archived" + lastTelemetry
+	default:
+		availableCondition.Status = metav1.ConditionUnknown
+		availableCondition.Reason = discovery.ReasonUnknownStatus
+		availableCondition.Message = fmt.Sprintf("Cluster status: %s", dc.Spec.Status)
+	}
+
+	return availableCondition
+}
+
+/*
+isUnreportedStatus reports whether a cluster with the given OCM subscription status and no activity timestamp is
+unreported, so that its availability cannot be observed. Removed clusters keep the reason reported by OCM, and
+provisioning clusters already have an Unknown availability.
+*/
+func isUnreportedStatus(status string) bool {
+	return status == discovery.SubscriptionStatusActive || status == discovery.SubscriptionStatusStale ||
+		status == discovery.SubscriptionStatusDisconnected
+}
+
+/*
+reportsTelemetry reports whether clusters with the given OCM subscription status are expected to send telemetry, so
+that their availability can be computed from their activity. Disconnected, provisioning and removed clusters keep the
+reason reported by OCM instead of being marked stale.
+*/
+func reportsTelemetry(status string) bool {
+	return status == discovery.SubscriptionStatusActive || status == discovery.SubscriptionStatusStale
+}
+
+// getManagedCluster returns the ManagedCluster created for the DiscoveredCluster, or nil if it cannot be found.
+func (r *DiscoveredClusterReconciler) getManagedCluster(ctx context.Context,
+	dc *discovery.DiscoveredCluster) *clusterapiv1.ManagedCluster {
+	if !r.Capabilities.Has(platform.ManagedCluster) {
+		return nil
+	}
+
+	mc := &clusterapiv1.ManagedCluster{}
+	name := discovery.GetManagedClusterName(dc)
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, mc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logf.Error(err, "failed to get ManagedCluster", "Name", name)
+		}
+		return nil
+	}
+	return mc
+}
+
+/*
+buildImportedCondition constructs the Imported condition of a DiscoveredCluster from the conditions of its
+ManagedCluster, so that users can see at which step the import of the cluster is stuck. A cluster whose Manual import
+has not been approved yet has no ManagedCluster and is reported as awaiting approval.
+*/
+func buildImportedCondition(dc *discovery.DiscoveredCluster, mc *clusterapiv1.ManagedCluster, awaitingApproval bool,
+	now time.Time) discovery.DiscoveredClusterCondition {
+	condition := discovery.DiscoveredClusterCondition{
+		Type:               discovery.ConditionImported,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	if mc == nil && awaitingApproval {
+		condition.Reason = discovery.ReasonAwaitingApproval
+		condition.Message = "Import prerequisites have been created and the import is waiting for approval"
+		return condition
+	}
+
+	if mc == nil {
+		condition.Reason = discovery.ReasonImportPending
+		condition.Message = "Waiting for the ManagedCluster to be created"
+		return condition
+	}
+
+	conditions := mc.Status.Conditions
+	importSucceeded := meta.FindStatusCondition(conditions, managedClusterConditionImportSucceeded)
+
+	switch {
+	case importSucceeded != nil && importSucceeded.Status == metav1.ConditionFalse &&
+		importSucceeded.Reason == managedClusterReasonImportFailed:
+		condition.Reason = discovery.ReasonImportFailed
+		condition.Message = fmt.Sprintf("Import of ManagedCluster %s failed: %s", mc.Name, importSucceeded.Message)
+	case meta.IsStatusConditionTrue(conditions, clusterapiv1.ManagedClusterConditionAvailable):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = discovery.ReasonManagedClusterAvailable
+		condition.Message = fmt.Sprintf("ManagedCluster %s has joined the hub and is available", mc.Name)
+	case meta.IsStatusConditionTrue(conditions, clusterapiv1.ManagedClusterConditionJoined):
+		condition.Reason = discovery.ReasonJoining
+		condition.Message = fmt.Sprintf("ManagedCluster %s has joined the hub and is not available yet", mc.Name)
+	case meta.IsStatusConditionTrue(conditions, clusterapiv1.ManagedClusterConditionHubAccepted):
+		condition.Reason = discovery.ReasonJoining
+		condition.Message = fmt.Sprintf("Waiting for the klusterlet of ManagedCluster %s to join the hub", mc.Name)
+	default:
+		condition.Reason = discovery.ReasonResourcesCreated
+		condition.Message = fmt.Sprintf("ManagedCluster %s has been created and is waiting to be accepted", mc.Name)
+	}
+
+	return condition
+}
+
+/*
+buildExpiringSoonCondition constructs the ExpiringSoon condition for a cluster with a trial subscription. The condition
+is true once the trial end date falls within the expiry window, and stays true after the trial has ended.
+*/
+func buildExpiringSoonCondition(dc *discovery.DiscoveredCluster, window time.Duration,
+	now time.Time) discovery.DiscoveredClusterCondition {
+	end := dc.Spec.TrialEndDate
+	condition := discovery.DiscoveredClusterCondition{
+		Type:               discovery.ConditionExpiringSoon,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	switch {
+	case !now.Before(end.Time):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = discovery.ReasonTrialExpired
+		condition.Message = fmt.Sprintf("Trial ended: %s", end.Format("2006-01-02 15:04:05 MST"))
+	case !now.Before(end.Add(-window)):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = discovery.ReasonTrialEndingSoon
+		condition.Message = fmt.Sprintf("Trial ends soon: %s", end.Format("2006-01-02 15:04:05 MST"))
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = discovery.ReasonTrialActive
+		condition.Message = fmt.Sprintf("Trial ends: %s", end.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	return condition
+}
+
+/*
+statusRefreshInterval returns how long to wait before the status conditions of the DiscoveredCluster are evaluated
+again. Conditions that depend on the current time are requeued for the moment they change, so they do not wait for
+the regular refresh interval or for the next change in OCM data.
+*/
+func statusRefreshInterval(dc *discovery.DiscoveredCluster, config *discovery.DiscoveryConfig,
+	now time.Time) time.Duration {
+	interval := recon.ShortRefreshInterval
+
+	thresholds := []time.Time{}
+	if end := dc.Spec.TrialEndDate; end != nil {
+		thresholds = append(thresholds, end.Add(-getTrialExpiryWindow(config)), end.Time)
+	}
+	if staleAfter := getStaleAfter(config); staleAfter != nil && dc.Spec.ActivityTimestamp != nil &&
+		reportsTelemetry(dc.Spec.Status) {
+		thresholds = append(thresholds, dc.Spec.ActivityTimestamp.Add(*staleAfter))
+	}
+
+	for _, t := range thresholds {
+		if d := t.Sub(now); d > 0 && d < interval {
+			interval = d
+		}
+	}
+
+	return interval
+}
+
+// isAwaitingApproval returns true if the import of the DiscoveredCluster has to be approved before it can proceed
+func isAwaitingApproval(dc *discovery.DiscoveredCluster, strategy string) bool {
+	return strategy == discovery.ImportStrategyManual && !dc.Spec.ImportApproved
+}
+
+// findCondition returns the condition of the given type, or nil if it is not present.
+func findCondition(conditions []discovery.DiscoveredClusterCondition,
+	conditionType string) *discovery.DiscoveredClusterCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// isConditionTrue reports whether the condition of the given type is present with status True.
+func isConditionTrue(conditions []discovery.DiscoveredClusterCondition, conditionType string) bool {
+	cond := findCondition(conditions, conditionType)
+	return cond != nil && cond.Status == metav1.ConditionTrue
+}
+
 // conditionEqual checks if two conditions are semantically equal
 func conditionEqual(a, b discovery.DiscoveredClusterCondition) bool {
 	return a.Type == b.Type &&
@@ -269,7 +728,7 @@ func (r *DiscoveredClusterReconciler) CreateAddOnDeploymentConfig(nn types.Names
 			Namespace: nn.Namespace,
 		},
 		Spec: addonv1alpha1.AddOnDeploymentConfigSpec{
-			AgentInstallNamespace: "open-cluster-management-discovered-hcp",
+			AgentInstallNamespace: discovery.DefaultHostedAddOnInstallNamespace,
 		},
 	}
 }
@@ -321,6 +780,37 @@ func (r *DiscoveredClusterReconciler) CreateAutoImportSecretServiceAccount(nn ty
 	}
 }
 
+// CreateAutoImportSecretKubeconfig creates an auto-import secret that imports the cluster with the given kubeconfig.
+func (r *DiscoveredClusterReconciler) CreateAutoImportSecretKubeconfig(nn types.NamespacedName, kubeconfig string,
+) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nn.Name,
+			Namespace: nn.Namespace,
+		},
+		StringData: map[string]string{
+			"kubeconfig": kubeconfig,
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+// CreateAutoImportSecretToken creates an auto-import secret that imports the cluster with the given token and server.
+func (r *DiscoveredClusterReconciler) CreateAutoImportSecretToken(nn types.NamespacedName, token, server string,
+) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nn.Name,
+			Namespace: nn.Namespace,
+		},
+		StringData: map[string]string{
+			"token":  token,
+			"server": server,
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
 /*
 createKlusterletAddonConfig creates a KlusterletAddonConfig object with the specified NamespacedName.
 It sets the basic configuration for the KlusterletAddonConfig, including metadata and spec fields.
@@ -398,7 +888,7 @@ func (r *DiscoveredClusterReconciler) CreateManagedCluster(nn types.NamespacedNa
 
 /*
 CreateManagedClusterSetBinding creates a ManagedClusterSetBinding object that binds
-the default ManagedClusterSet to the specified namespace. This enables clusters in the
+the ManagedClusterSet with the same name to the specified namespace. This enables clusters in the
 ManagedClusterSet to be managed from the given namespace.
 */
 func (r *DiscoveredClusterReconciler) CreateManagedClusterSetBinding(nn types.NamespacedName,
@@ -409,7 +899,7 @@ func (r *DiscoveredClusterReconciler) CreateManagedClusterSetBinding(nn types.Na
 			Namespace: nn.Namespace,
 		},
 		Spec: clusterapiv1beta2.ManagedClusterSetBindingSpec{
-			ClusterSet: DefaultName,
+			ClusterSet: nn.Name,
 		},
 	}
 }
@@ -427,7 +917,7 @@ func (r *DiscoveredClusterReconciler) CreateNamespaceForDiscoveredCluster(dc dis
 			Kind:       "Namespace",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: dc.Spec.DisplayName,
+			Name: discovery.GetManagedClusterName(&dc),
 			Labels: map[string]string{
 				discovery.ClusterMonitoringLabel: "true",
 			},
@@ -470,15 +960,15 @@ func (r *DiscoveredClusterReconciler) CreatePlacement(nn types.NamespacedName) *
 }
 
 /*
-EnsureAddOnDeploymentConfig ensures the existence of a AddOnDeploymentConfig resource for the given
-DiscoveredCluster. It checks if a AddOnDeploymentConfig with the specified name exists.
-If not found, it creates a new AddOnDeploymentConfig with the name and default configurations.
-If creation fails, it logs an error and returns with a requeue signal.
-If the AddOnDeploymentConfig already exists or if an error occurs during retrieval, it logs an error and returns
-with a requeue signal.
+EnsureAddOnDeploymentConfig ensures the existence of a AddOnDeploymentConfig resource for the hosted addons.
+It checks if a AddOnDeploymentConfig with the specified name exists.
+If not found, it creates a new AddOnDeploymentConfig with the name and the given settings.
+If it exists with different settings, it updates the agent install namespace and node placement.
+If creation or update fails, it logs an error and returns with a requeue signal.
+If an error occurs during retrieval, it logs an error and returns with a requeue signal.
 */
-func (r *DiscoveredClusterReconciler) EnsureAddOnDeploymentConfig(ctx context.Context) (
-	ctrl.Result, error) {
+func (r *DiscoveredClusterReconciler) EnsureAddOnDeploymentConfig(ctx context.Context,
+	settings discovery.HostedAddOnSettings) (ctrl.Result, error) {
 	nn := types.NamespacedName{Name: AddOnDeploymentConfigName, Namespace: os.Getenv("POD_NAMESPACE")}
 	existingADC := addonv1alpha1.AddOnDeploymentConfig{}
 
@@ -486,6 +976,7 @@ func (r *DiscoveredClusterReconciler) EnsureAddOnDeploymentConfig(ctx context.Co
 		logf.Info("Creating AddOnDeploymentConfig", "Name", nn.Name, "Namespace", nn.Namespace)
 
 		adc := r.CreateAddOnDeploymentConfig(nn)
+		applyHostedAddOnSettings(adc, settings)
 		if err := r.Create(ctx, adc); err != nil {
 			logf.Error(err, "failed to create AddOnDeploymentConfig", "Name", nn.Name)
 			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
@@ -494,6 +985,17 @@ func (r *DiscoveredClusterReconciler) EnsureAddOnDeploymentConfig(ctx context.Co
 	} else if err != nil {
 		logf.Error(err, "failed to get AddOnDeploymentConfig", "Name", nn.Name)
 		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+
+	} else {
+		adc := existingADC.DeepCopy()
+		applyHostedAddOnSettings(adc, settings)
+		if !equality.Semantic.DeepEqual(existingADC.Spec, adc.Spec) {
+			logf.Info("Updating AddOnDeploymentConfig", "Name", nn.Name, "Namespace", nn.Namespace)
+			if err := r.Update(ctx, adc); err != nil {
+				logf.Error(err, "failed to update AddOnDeploymentConfig", "Name", nn.Name)
+				return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+			}
+		}
 	}
 
 	return ctrl.Result{}, nil
@@ -501,11 +1003,10 @@ func (r *DiscoveredClusterReconciler) EnsureAddOnDeploymentConfig(ctx context.Co
 
 /*
 EnsureAutoImportSecret ensures the existence of an auto-import secret for the given DiscoveredCluster and
-DiscoveryConfig. It checks if an auto-import secret with the specified name and namespace exists.
-If not found, it creates a new auto-import secret with the name "auto-import-secret" and the DiscoveredCluster's
-namespace. It sets a controller reference to the DiscoveredCluster for ownership management.
-If creation fails, it logs an error and returns with a requeue signal. If the auto-import secret already exists or if
-an error occurs during retrieval, it logs an error and returns with a requeue signal.
+DiscoveryConfig. It reads the OCM credential referenced by the DiscoveredCluster and builds an auto-import secret named
+"auto-import-secret" in the namespace of the ManagedCluster from it. The auto-import secret is updated when the
+credential is rotated before the cluster is imported. If the credential cannot be read or parsed, it logs an error and
+returns with a requeue signal.
 */
 func (r *DiscoveredClusterReconciler) EnsureAutoImportSecret(ctx context.Context, dc discovery.DiscoveredCluster) (
 	ctrl.Result, error) {
@@ -521,95 +1022,212 @@ func (r *DiscoveredClusterReconciler) EnsureAutoImportSecret(ctx context.Context
 		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
 	}
 
-	if authRequest, err := parseSecretForAuth(&existingSecret); err == nil {
-		nn := types.NamespacedName{Name: "auto-import-secret", Namespace: dc.Spec.DisplayName}
-		existingSecret = corev1.Secret{}
+	authRequest, err := auth.ParseSecretForAuth(&existingSecret)
+	if err != nil {
+		logf.Error(err, "failed to parse token from Secret", "Name", nn.Name)
+		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+	}
 
-		if err := r.Get(ctx, nn, &existingSecret, &client.GetOptions{}); apierrors.IsNotFound(err) {
-			logf.Info("Creating auto-import-secret for managed cluster", "Namespace", nn.Namespace)
+	nn = types.NamespacedName{Name: "auto-import-secret", Namespace: discovery.GetManagedClusterName(&dc)}
 
-			var s *corev1.Secret
-			switch authRequest.AuthMethod {
-			case "service-account":
-				s = r.CreateAutoImportSecretServiceAccount(nn, dc.Spec.RHOCMClusterID, authRequest.ID, authRequest.Secret)
-			case "offline-token":
-				s = r.CreateAutoImportSecretOfflineToken(nn, dc.Spec.RHOCMClusterID, authRequest.Token)
-			default:
-				logf.V(1).Info("Invalid authentication method", "method", authRequest.AuthMethod)
-				return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, fmt.Errorf("invalid authentication configuration")
-			}
-			if err := r.Create(ctx, s); err != nil {
-				logf.Error(err, "failed to create auto-import Secret for ManagedCluster", "Name", nn.Name)
-				return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
-			}
+	var s *corev1.Secret
+	switch authRequest.AuthMethod {
+	case "service-account":
+		s = r.CreateAutoImportSecretServiceAccount(nn, dc.Spec.RHOCMClusterID, authRequest.ID, authRequest.Secret)
+	case "offline-token":
+		s = r.CreateAutoImportSecretOfflineToken(nn, dc.Spec.RHOCMClusterID, authRequest.Token)
+	default:
+		logf.V(1).Info("Invalid authentication method", "method", authRequest.AuthMethod)
+		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, fmt.Errorf("invalid authentication configuration")
+	}
 
-		} else if err != nil {
-			logf.Error(err, "failed to get auto-import Secret for ManagedCluster", "Name", nn.Name)
-			return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
-		}
-	} else {
-		logf.Error(err, "failed to parse token from Secret", "Name", nn.Name)
+	setCreatedBy(s, dc)
+	if err := r.applyAutoImportSecret(ctx, dc, s); err != nil {
+		logf.Error(err, "failed to apply auto-import Secret for ManagedCluster", "Name", nn.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+/*
+applyAutoImportSecret creates the auto-import secret, or updates its data when the credential it is built from was
+rotated, so that the next import attempt uses the current credential. An auto-import secret that was not created for
+the DiscoveredCluster, such as one created by a user, is left unchanged and a warning event is recorded instead.
+*/
+func (r *DiscoveredClusterReconciler) applyAutoImportSecret(ctx context.Context, dc discovery.DiscoveredCluster,
+	desired *corev1.Secret) error {
+	existing := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing); apierrors.IsNotFound(err) {
+		logf.Info("Creating auto-import-secret for managed cluster", "Namespace", desired.Namespace)
+		return r.Create(ctx, desired)
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get Secret %s/%s", desired.Namespace, desired.Name)
+	}
+
+	data := map[string][]byte{}
+	for key, value := range desired.Data {
+		data[key] = value
+	}
+	for key, value := range desired.StringData {
+		data[key] = []byte(value)
+	}
+	if equality.Semantic.DeepEqual(existing.Data, data) {
+		return nil
+	}
+
+	if !isCreatedBy(existing, dc) {
+		logf.Info("Leaving auto-import-secret that was not created by discovery unchanged", "Namespace",
+			desired.Namespace)
+		r.Recorder.Eventf(&dc, corev1.EventTypeWarning, EventReasonAutoImportSecretUnmanaged,
+			"Secret %s/%s was not created by discovery, so it is not updated with the current import credential",
+			desired.Namespace, desired.Name)
+		return nil
+	}
+
+	logf.Info("Updating auto-import-secret for managed cluster", "Namespace", desired.Namespace)
+	existing.Data = data
+	return r.Update(ctx, existing)
+}
+
+/*
+EnsureCredentialImport ensures all required resources exist for automatically importing a self-managed cluster
+(e.g. OCP, OSD, ARO) with a user-supplied credential. It creates a dedicated namespace for the cluster and then calls
+EnsureCommonResources to create the standard import resources and the AutoImportSecret built from the credential.
+*/
+func (r *DiscoveredClusterReconciler) EnsureCredentialImport(ctx context.Context, dc *discovery.DiscoveredCluster) (
+	ctrl.Result, error) {
+	if res, err := r.EnsureNamespaceForDiscoveredCluster(ctx, *dc); err != nil {
+		logf.Error(err, "failed to ensure namespace for DiscoveredCluster", "Name", dc.Spec.DisplayName)
+		return res, err
+	}
+
+	return r.EnsureCommonResources(ctx, dc, false)
+}
+
+/*
+EnsureImportCredentialAutoImportSecret ensures the existence of an auto-import secret built from the import credential
+of the given DiscoveredCluster. The credential is referenced by the DiscoveredCluster itself, or mapped to it in the
+DiscoveryConfig. A credential with a kubeconfig key produces a kubeconfig auto-import secret, and a credential with
+token and server keys produces a token auto-import secret. The auto-import secret is updated when the credential is
+rotated before the cluster is imported.
+*/
+func (r *DiscoveredClusterReconciler) EnsureImportCredentialAutoImportSecret(ctx context.Context,
+	dc discovery.DiscoveredCluster) (ctrl.Result, error) {
+	credentialName := r.getImportCredentialName(ctx, dc)
+	if credentialName == "" {
+		err := fmt.Errorf("no import credential configured for DiscoveredCluster %s", dc.Name)
+		logf.Error(err, "failed to find import credential", "Name", dc.Spec.DisplayName)
 		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
 	}
 
+	nn := types.NamespacedName{Name: credentialName, Namespace: dc.Namespace}
+	credential := corev1.Secret{}
+
+	if err := r.Get(ctx, nn, &credential); apierrors.IsNotFound(err) {
+		logf.Error(err, "Secret was not found", "Name", nn.Name, "Namespace", nn.Namespace)
+		return ctrl.Result{RequeueAfter: recon.ShortRefreshInterval}, err
+
+	} else if err != nil {
+		logf.Error(err, "failed to get Secret", "Name", nn.Name, "Namespace", nn.Namespace)
+		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+	}
+
+	nn = types.NamespacedName{Name: "auto-import-secret", Namespace: discovery.GetManagedClusterName(&dc)}
+
+	var s *corev1.Secret
+	kubeconfig, kubeconfigOk := credential.Data["kubeconfig"]
+	token, tokenOk := credential.Data["token"]
+	server, serverOk := credential.Data["server"]
+
+	switch {
+	case kubeconfigOk:
+		s = r.CreateAutoImportSecretKubeconfig(nn, string(kubeconfig))
+	case tokenOk && serverOk:
+		s = r.CreateAutoImportSecretToken(nn, strings.TrimSuffix(string(token), "\n"),
+			strings.TrimSuffix(string(server), "\n"))
+	default:
+		err := fmt.Errorf("%s: bad format: secret must contain kubeconfig, or token and server", credential.Name)
+		logf.Error(err, "failed to parse import credential", "Name", credential.Name)
+		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+	}
+
+	setCreatedBy(s, dc)
+	if err := r.applyAutoImportSecret(ctx, dc, s); err != nil {
+		logf.Error(err, "failed to apply auto-import Secret for ManagedCluster", "Name", nn.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
+/*
+getImportCredentialName returns the name of the Secret used to import the DiscoveredCluster. A credential referenced by
+the DiscoveredCluster takes precedence over a credential mapped to it in the DiscoveryConfig. Returns an empty string if
+no credential is configured.
+*/
+func (r *DiscoveredClusterReconciler) getImportCredentialName(ctx context.Context,
+	dc discovery.DiscoveredCluster) string {
+	if dc.Spec.ImportCredential != nil && dc.Spec.ImportCredential.Name != "" {
+		return dc.Spec.ImportCredential.Name
+	}
+
+	if config := r.getDiscoveryConfig(ctx, dc.Namespace); config != nil {
+		for _, m := range config.Spec.ImportCredentials {
+			if m.ClusterName == dc.Spec.DisplayName {
+				return m.SecretName
+			}
+		}
+	}
+	return ""
+}
+
 /*
 EnsureCommonResources ensures all required resources exist for automatically importing a cluster.
 For HCP (Hosted Control Plane) clusters, it creates:
-  - ManagedClusterSetBinding to bind clusters to the operator namespace
+  - ManagedClusterSetBinding to bind the ManagedClusterSet of the cluster to the Placement namespace
   - Placement for cluster selection
   - AddOnDeploymentConfig for addon configuration
-  - Placement references in ClusterManagementAddOns (cluster-proxy, managed-serviceaccount, work-manager)
+  - Placement references in the ClusterManagementAddOns of the hosted addons (by default cluster-proxy,
+    managed-serviceaccount, work-manager)
 
 For all cluster types, it creates:
   - ManagedCluster resource
   - KlusterletAddonConfig (if CRD exists)
 
-For non-HCP clusters, it additionally creates:
+For ROSA clusters, it additionally creates:
   - Credential Secret for the discovered cluster
   - AutoImportSecret for ROSA authentication
 
+For self-managed clusters (e.g. OCP, OSD, ARO), it additionally creates:
+  - AutoImportSecret from the import credential supplied by the user
+
 Returns an error if any resource creation fails.
 */
 func (r *DiscoveredClusterReconciler) EnsureCommonResources(ctx context.Context,
 	dc *discovery.DiscoveredCluster, isHCP bool) (ctrl.Result, error) {
 	if isHCP {
-		if res, err := r.EnsureManagedClusterSetBinding(ctx); err != nil {
-			logf.Error(err, "failed to ensure ManagedClusterBindingSet created", "Name", DefaultName,
-				"Namespace", os.Getenv("POD_NAMESPACE"))
+		if res, err := r.ensureHostedAddOns(ctx, *dc); err != nil {
 			return res, err
 		}
-
-		if res, err := r.EnsurePlacement(ctx); err != nil {
-			logf.Error(err, "failed to ensure Placement created", "Name", DefaultName,
-				"Namespace", os.Getenv("POD_NAMESPACE"))
-			return res, err
-		}
-
-		if res, err := r.EnsureAddOnDeploymentConfig(ctx); err != nil {
-			logf.Error(err, "failed to ensure AddOnDeploymentConfig created", "Name", AddOnDeploymentConfigName,
-				"Namespace", os.Getenv("POD_NAMESPACE"))
-			return res, err
-		}
-
-		addonNames := []string{"cluster-proxy", "managed-serviceaccount", "work-manager"}
-		for _, addon := range addonNames {
-			if res, err := r.AddPlacementToClusterManagementAddOn(ctx, addon); err != nil {
-				return res, err
-			}
-		}
 	}
 
-	if res, err := r.EnsureManagedCluster(ctx, *dc); err != nil {
+	// With the Manual import strategy, the ManagedCluster is only created once the import has been approved.
+	config := r.getDiscoveryConfig(ctx, dc.Namespace)
+	if isAwaitingApproval(dc, discovery.EffectiveImportStrategy(dc, config)) {
+		logf.Info("Waiting for the import to be approved. Skipping ManagedCluster creation.",
+			"Name", dc.Spec.DisplayName)
+	} else if res, err := r.EnsureManagedCluster(ctx, *dc); err != nil {
 		logf.Error(err, "failed to ensure ManagedCluster created", "Name", dc.Spec.DisplayName)
 		return res, err
 	}
 
 	// Ensure that the KlusterletAddOnConfig CRD exists. In standalone MCE mode, the CRD is not deployed.
 	crdName := "klusterletaddonconfigs.agent.open-cluster-management.io"
-	if res, err := r.EnsureCRDExist(ctx, crdName); err != nil {
+	if !r.Capabilities.Has(platform.KlusterletAddonConfig) {
+		logf.V(1).Info("KlusterletAddonConfig API is not served by the hub. Skipping KlusterletAddonConfig creation.",
+			"Name", dc.Spec.DisplayName)
+	} else if res, err := r.EnsureCRDExist(ctx, crdName); err != nil {
 		if !apierrors.IsNotFound(err) {
 			logf.Error(err, "failed to ensure custom resource definition exist", "Name", crdName)
 			return res, err
@@ -621,7 +1239,13 @@ func (r *DiscoveredClusterReconciler) EnsureCommonResources(ctx context.Context,
 		}
 	}
 
-	if !isHCP {
+	if !isHCP && discovery.IsCredentialImportClusterType(dc.Spec.Type) {
+		// Self-managed clusters are imported with the credential supplied by the user.
+		if res, err := r.EnsureImportCredentialAutoImportSecret(ctx, *dc); err != nil {
+			logf.Error(err, "failed to ensure auto import Secret created", "Name", dc.Spec.DisplayName)
+			return res, err
+		}
+	} else if !isHCP {
 		// Ensure that the DiscoveredCluster credentials are available on the cluster.
 		if res, err := r.EnsureDiscoveredClusterCredentialExists(ctx, *dc); err != nil {
 			logf.Error(err, "failed to ensure DiscoveredCluster credential Secret exist", "Name",
@@ -692,13 +1316,16 @@ with a requeue signal.
 */
 func (r *DiscoveredClusterReconciler) EnsureKlusterletAddonConfig(ctx context.Context, dc discovery.DiscoveredCluster) (
 	ctrl.Result, error) {
-	nn := types.NamespacedName{Name: dc.Spec.DisplayName, Namespace: dc.Spec.DisplayName}
+	name := discovery.GetManagedClusterName(&dc)
+	nn := types.NamespacedName{Name: name, Namespace: name}
 	existingKAC := agentv1.KlusterletAddonConfig{}
 
 	if err := r.Get(ctx, nn, &existingKAC); apierrors.IsNotFound(err) {
 		logf.Info("Creating KlusterletAddonConfig", "Name", nn.Name, "Namespace", nn.Namespace)
 
 		kac := r.CreateKlusterletAddonConfig(nn)
+		applyKlusterletAddonConfigTemplate(kac, r.getKlusterletAddonConfigTemplate(ctx, dc))
+		setCreatedBy(kac, dc)
 		if err := r.Create(ctx, kac); err != nil {
 			logf.Error(err, "failed to create KlusterAddonConfig", "Name", nn.Name, "Namespace", nn.Namespace)
 			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
@@ -724,18 +1351,31 @@ requeue signal.
 func (r *DiscoveredClusterReconciler) EnsureManagedCluster(ctx context.Context, dc discovery.DiscoveredCluster) (
 	ctrl.Result, error) {
 	// ManagedCluster resources are cluster scoped resources; therefore we do not need to specify the namespace.
-	nn := types.NamespacedName{Name: dc.Spec.DisplayName}
+	nn := types.NamespacedName{Name: discovery.GetManagedClusterName(&dc)}
 	existingMC := &clusterapiv1.ManagedCluster{}
 
 	if err := r.Get(ctx, nn, existingMC); apierrors.IsNotFound(err) {
 		logf.Info("Creating ManagedCluster", "Name", nn.Name)
 		nn.Namespace = dc.GetNamespace() // We are setting the namespace only for annotation purposes.
 
+		// The klusterlet cannot be deployed with a KlusterletConfig that does not exist.
+		settings := r.getManagedClusterSettings(ctx, dc)
+		if missing, err := r.getMissingKlusterletConfig(ctx, settings); err != nil {
+			return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+		} else if missing != "" {
+			return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval},
+				fmt.Errorf("KlusterletConfig %s referenced to import cluster %s does not exist", missing, nn.Name)
+		}
+
 		mc := r.CreateManagedCluster(nn, dc.Spec.Type)
+		applyManagedClusterSettings(mc, settings)
+		setCreatedBy(mc, dc)
 		if err := r.Create(ctx, mc); err != nil {
 			logf.Error(err, "failed to create ManagedCluster", "Name", nn.Name)
 			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
 		}
+		r.Recorder.Eventf(&dc, corev1.EventTypeNormal, EventReasonImportStarted,
+			"Created ManagedCluster %s to import the cluster", mc.Name)
 
 	} else if err != nil {
 		logf.Error(err, "failed to get ManagedCluster", "Name", nn.Name)
@@ -746,15 +1386,16 @@ func (r *DiscoveredClusterReconciler) EnsureManagedCluster(ctx context.Context,
 }
 
 /*
-EnsureManagedClusterSetBinding ensures the existence of a ManagedClusterSetBinding.
-It checks if a ManagedClusterSetBinding with the specified display name exists.
-If not found, it creates a new ManagedClusterSetBinding with the display name and default configurations.
+EnsureManagedClusterSetBinding ensures the existence of a ManagedClusterSetBinding for the given ManagedClusterSet.
+It checks if a ManagedClusterSetBinding with the name of the ManagedClusterSet exists in the given namespace.
+If not found, it creates a new ManagedClusterSetBinding that binds the ManagedClusterSet to the namespace.
 If creation fails, it logs an error and returns with a requeue signal.
 If the ManagedClusterSetBinding already exists or if an error occurs during retrieval, it logs an error and returns
 with a requeue signal.
 */
-func (r *DiscoveredClusterReconciler) EnsureManagedClusterSetBinding(ctx context.Context) (ctrl.Result, error) {
-	nn := types.NamespacedName{Name: DefaultName, Namespace: os.Getenv("POD_NAMESPACE")}
+func (r *DiscoveredClusterReconciler) EnsureManagedClusterSetBinding(ctx context.Context, clusterSet,
+	namespace string) (ctrl.Result, error) {
+	nn := types.NamespacedName{Name: clusterSet, Namespace: namespace}
 	existingMCSB := &clusterapiv1beta2.ManagedClusterSetBinding{}
 
 	if err := r.Get(ctx, nn, existingMCSB); apierrors.IsNotFound(err) {
@@ -795,13 +1436,14 @@ If an error occurs during retrieval or creation, it logs an error and returns wi
 */
 func (r *DiscoveredClusterReconciler) EnsureNamespaceForDiscoveredCluster(ctx context.Context,
 	dc discovery.DiscoveredCluster) (ctrl.Result, error) {
-	nn := types.NamespacedName{Name: dc.Spec.DisplayName}
+	nn := types.NamespacedName{Name: discovery.GetManagedClusterName(&dc)}
 	existingNs := &corev1.Namespace{}
 
 	if err := r.Get(ctx, nn, existingNs); apierrors.IsNotFound(err) {
 		logf.Info("Creating Namespace for DiscoveredCluster", "Name", nn.Name)
 
 		ns := r.CreateNamespaceForDiscoveredCluster(dc)
+		setCreatedBy(ns, dc)
 		if err := r.Create(ctx, ns); err != nil {
 			logf.Error(err, "failed to create Namespace", "Name", nn.Name)
 			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
@@ -816,13 +1458,14 @@ func (r *DiscoveredClusterReconciler) EnsureNamespaceForDiscoveredCluster(ctx co
 }
 
 /*
-EnsurePlacement ensures a Placement resource exists in the operator namespace.
+EnsurePlacement ensures a Placement resource with the given NamespacedName exists.
 The Placement is used to select which ManagedClusters should have certain addons installed.
-If the Placement doesn't exist, it creates one with the default configuration.
+If the Placement doesn't exist, it creates one with the default configuration. An existing Placement is left unchanged,
+since it may be shared with other workloads.
 Returns an error if creation or retrieval fails.
 */
-func (r *DiscoveredClusterReconciler) EnsurePlacement(ctx context.Context) (ctrl.Result, error) {
-	nn := types.NamespacedName{Name: DefaultName, Namespace: os.Getenv("POD_NAMESPACE")}
+func (r *DiscoveredClusterReconciler) EnsurePlacement(ctx context.Context, nn types.NamespacedName) (
+	ctrl.Result, error) {
 	existingPlacement := &clusterapiv1beta1.Placement{}
 
 	if err := r.Get(ctx, nn, existingPlacement); apierrors.IsNotFound(err) {
@@ -859,13 +1502,14 @@ func (r *DiscoveredClusterReconciler) EnsureROSA(ctx context.Context, dc *discov
 }
 
 /*
-AddPlacementToClusterManagementAddOn adds the default Placement reference to a ClusterManagementAddOn's
+AddPlacementToClusterManagementAddOn adds the Placement reference of the hosted addons to a ClusterManagementAddOn's
 install strategy if not already present. This ensures the addon is installed on clusters selected by
 the Placement. It also sets the install strategy type to "Placements" and adds the AddOnDeploymentConfig
-reference for addon configuration. Used for addons like cluster-proxy, managed-serviceaccount, and work-manager.
+reference for addon configuration. References to a previously configured Placement of the hosted addons are replaced.
+Used for addons like cluster-proxy, managed-serviceaccount, and work-manager.
 */
-func (r *DiscoveredClusterReconciler) AddPlacementToClusterManagementAddOn(ctx context.Context, name string) (
-	ctrl.Result, error) {
+func (r *DiscoveredClusterReconciler) AddPlacementToClusterManagementAddOn(ctx context.Context, name string,
+	settings discovery.HostedAddOnSettings) (ctrl.Result, error) {
 	cma := &addonv1alpha1.ClusterManagementAddOn{}
 
 	if err := r.Get(ctx, types.NamespacedName{Name: name}, cma); err != nil {
@@ -873,25 +1517,26 @@ func (r *DiscoveredClusterReconciler) AddPlacementToClusterManagementAddOn(ctx c
 		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
 	}
 
-	if cma.Spec.InstallStrategy.Type != "Placements" {
-		cma.Spec.InstallStrategy.Type = "Placements"
-	}
+	desired := cma.DeepCopy()
+	desired.Spec.InstallStrategy.Type = "Placements"
 
-	placements := cma.Spec.InstallStrategy.Placements
+	placements := []addonv1alpha1.PlacementStrategy{}
 	placementAvailable := false
 
-	for _, p := range placements {
-		if p.Name == DefaultName && p.Namespace == os.Getenv("POD_NAMESPACE") {
+	for _, p := range desired.Spec.InstallStrategy.Placements {
+		if p.Name == settings.PlacementName && p.Namespace == settings.PlacementNamespace {
 			placementAvailable = true
-			break
+		} else if isHostedAddOnPlacementStrategy(p) {
+			continue
 		}
+		placements = append(placements, p)
 	}
 
 	if !placementAvailable {
 		placement := addonv1alpha1.PlacementStrategy{
 			PlacementRef: addonv1alpha1.PlacementRef{
-				Name:      DefaultName,
-				Namespace: os.Getenv("POD_NAMESPACE"),
+				Name:      settings.PlacementName,
+				Namespace: settings.PlacementNamespace,
 			},
 			Configs: []addonv1alpha1.AddOnConfig{
 				{
@@ -906,9 +1551,12 @@ func (r *DiscoveredClusterReconciler) AddPlacementToClusterManagementAddOn(ctx c
 				},
 			},
 		}
-		cma.Spec.InstallStrategy.Placements = append(cma.Spec.InstallStrategy.Placements, placement)
+		placements = append(placements, placement)
+	}
+	desired.Spec.InstallStrategy.Placements = placements
 
-		if err := r.Update(ctx, cma); err != nil {
+	if !equality.Semantic.DeepEqual(cma.Spec, desired.Spec) {
+		if err := r.Update(ctx, desired); err != nil {
 			logf.Error(err, "failed to patch ClusterManagementAddOn", "Name", cma.GetName())
 			return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
 		}
@@ -920,8 +1568,65 @@ func (r *DiscoveredClusterReconciler) AddPlacementToClusterManagementAddOn(ctx c
 // SetupWithManager sets up the controller with the Manager.
 // Reconciles all DiscoveredCluster events to ensure status conditions are updated for all cluster types.
 // Auto-import logic is protected by webhook validation and type checking in the Reconcile function.
+// ManagedClusters are only watched when the hub serves the ManagedCluster API.
+// DiscoveredClusters are indexed by the ManagedCluster name recorded in their status to detect name conflicts.
 func (r *DiscoveredClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &discovery.DiscoveredCluster{},
+		managedClusterNameIndex, indexManagedClusterName); err != nil {
+		return errors.Wrap(err, "failed to index DiscoveredClusters by ManagedCluster name")
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&discovery.DiscoveredCluster{}).
-		Complete(r)
+		Watches(&discovery.DiscoveryConfig{}, handler.EnqueueRequestsFromMapFunc(r.namespaceToDiscoveredClusters)).
+		Watches(&discovery.DiscoveryImportPolicy{}, handler.EnqueueRequestsFromMapFunc(r.namespaceToDiscoveredClusters))
+
+	if r.Capabilities.Has(platform.ManagedCluster) {
+		b = b.Watches(&clusterapiv1.ManagedCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.managedClusterToDiscoveredClusters))
+	}
+	return b.Complete(r)
+}
+
+/*
+managedClusterToDiscoveredClusters maps a ManagedCluster to the DiscoveredClusters imported under its name, so that
+their Imported condition follows the status of the ManagedCluster.
+*/
+func (r *DiscoveredClusterReconciler) managedClusterToDiscoveredClusters(ctx context.Context,
+	obj client.Object) []reconcile.Request {
+	discoveredClusters := &discovery.DiscoveredClusterList{}
+	if err := r.List(ctx, discoveredClusters,
+		client.MatchingFields{managedClusterNameIndex: obj.GetName()}); err != nil {
+		logf.Error(err, "failed to list DiscoveredClusters", "ManagedCluster", obj.GetName())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, dc := range discoveredClusters.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace},
+		})
+	}
+	return requests
+}
+
+/*
+namespaceToDiscoveredClusters maps a DiscoveryConfig or DiscoveryImportPolicy to the DiscoveredClusters in its
+namespace, so that changes to their import settings are reconciled for all of them.
+*/
+func (r *DiscoveredClusterReconciler) namespaceToDiscoveredClusters(ctx context.Context,
+	obj client.Object) []reconcile.Request {
+	discoveredClusters := &discovery.DiscoveredClusterList{}
+	if err := r.List(ctx, discoveredClusters, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.Error(err, "failed to list DiscoveredClusters", "Namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, dc := range discoveredClusters.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace},
+		})
+	}
+	return requests
 }
@@ -19,28 +19,40 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	klusterletconfigv1alpha1 "github.com/stolostron/cluster-lifecycle-api/klusterletconfig/v1alpha1"
 	discovery "github.com/stolostron/discovery/api/v1"
+	"github.com/stolostron/discovery/pkg/platform"
+	utils "github.com/stolostron/discovery/util"
+	recon "github.com/stolostron/discovery/util/reconciler"
 	agentv1 "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
 	clusterapiv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	clusterapiv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 var r = &DiscoveredClusterReconciler{
-	Client: fake.NewClientBuilder().Build(),
+	Client:   fake.NewClientBuilder().Build(),
+	Recorder: &record.FakeRecorder{},
 }
 
 var crdDir = "../test/resources/"
@@ -53,6 +65,7 @@ func registerScheme() {
 	addonv1alpha1.AddToScheme(scheme.Scheme)
 	clusterapiv1beta1.AddToScheme(scheme.Scheme)
 	clusterapiv1beta2.AddToScheme(scheme.Scheme)
+	klusterletconfigv1alpha1.AddToScheme(scheme.Scheme)
 }
 
 func deployCRDs(directory string) error {
@@ -181,6 +194,8 @@ func Test_DiscoveredCluster_Reconciler_Reconcile(t *testing.T) {
 	}
 
 	registerScheme()
+	r.Client = fake.NewClientBuilder().
+		WithIndex(&discovery.DiscoveredCluster{}, managedClusterNameIndex, indexManagedClusterName).Build()
 	if err := deployCRDs(crdDir); err != nil {
 		t.Errorf("failed to deploy CRDs: %v", err)
 	}
@@ -241,9 +256,109 @@ func Test_DiscoveredCluster_Reconciler_Reconcile(t *testing.T) {
 				Namespace: tt.dc.Spec.DisplayName}, s); err != nil {
 				t.Errorf("failed to get auto-import Secret: %v", err)
 			}
+
+			if s.Labels[utils.LabelCreatedBy] != string(tt.dc.UID) {
+				t.Errorf("auto-import Secret is not labeled as created for the DiscoveredCluster: %v", s.Labels)
+			}
+
+			dc := &discovery.DiscoveredCluster{}
+			if err := r.Get(context.TODO(), tt.req.NamespacedName, dc); err != nil {
+				t.Errorf("failed to get DiscoveredCluster: %v", err)
+			}
+
+			if !controllerutil.ContainsFinalizer(dc, discovery.ImportCleanUpFinalizer) {
+				t.Errorf("DiscoveredCluster is missing the %s finalizer", discovery.ImportCleanUpFinalizer)
+			}
+
+			// Remove the finalizer so that the DiscoveredCluster can be deleted.
+			controllerutil.RemoveFinalizer(dc, discovery.ImportCleanUpFinalizer)
+			if err := r.Update(context.TODO(), dc); err != nil {
+				t.Errorf("failed to remove finalizer from DiscoveredCluster: %v", err)
+			}
 		})
 	}
 }
+
+func Test_DiscoveredCluster_Reconciler_Reconcile_WithoutOCMAPIs(t *testing.T) {
+	// The scheme of a hub without OCM does not register the OCM types.
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+	if err := discovery.AddToScheme(s); err != nil {
+		t.Fatalf("failed to register scheme: %v", err)
+	}
+
+	dc := &discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "310ac28e-b69b-447b-a51f-08e967cff1ee", Namespace: "discovery"},
+		Spec: discovery.DiscoveredClusterSpec{
+			DisplayName:            "fake-cluster",
+			ImportAsManagedCluster: true,
+			Type:                   "ROSA",
+		},
+	}
+	reconciler := &DiscoveredClusterReconciler{
+		Client:       fake.NewClientBuilder().WithScheme(s).WithObjects(dc).WithStatusSubresource(dc).Build(),
+		Recorder:     &record.FakeRecorder{},
+		Capabilities: platform.NewCapabilities(),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace}}
+	if _, err := reconciler.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v, want the import to be skipped", err)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := reconciler.Get(context.TODO(), types.NamespacedName{Name: "fake-cluster"}, ns); !apierrors.IsNotFound(err) {
+		t.Errorf("Reconcile() created import resources although the ManagedCluster API is not served: %v", err)
+	}
+
+	got := &discovery.DiscoveredCluster{}
+	if err := reconciler.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatalf("failed to get DiscoveredCluster: %v", err)
+	}
+	if imported := findCondition(got.Status.Conditions, discovery.ConditionImported); imported == nil ||
+		imported.Reason != discovery.ReasonManagedClusterAPINotServed {
+		t.Errorf("Imported condition = %+v, want reason %s", imported, discovery.ReasonManagedClusterAPINotServed)
+	}
+	if nameConflict := findCondition(got.Status.Conditions, discovery.ConditionNameConflict); nameConflict != nil {
+		t.Errorf("NameConflict condition = %+v, want none", nameConflict)
+	}
+}
+
+func Test_Reconciler_cleanupImportArtifacts(t *testing.T) {
+	registerScheme()
+	dc := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar", UID: "dc-uid"},
+		Spec:       discovery.DiscoveredClusterSpec{DisplayName: "foo"},
+	}
+	createdBy := map[string]string{utils.LabelCreatedBy: string(dc.UID)}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: createdBy}}
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "auto-import-secret", Namespace: "foo", Labels: createdBy}}
+	mc := &clusterapiv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: createdBy}}
+	kac := &agentv1.KlusterletAddonConfig{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "foo"}}
+
+	cr := &DiscoveredClusterReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ns, s, mc, kac).Build(),
+		Recorder: &record.FakeRecorder{},
+	}
+
+	if err := cr.cleanupImportArtifacts(context.TODO(), dc); err != nil {
+		t.Fatalf("failed to clean up import artifacts: %v", err)
+	}
+
+	for _, obj := range []client.Object{ns, s, mc} {
+		if err := cr.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("expected %T %s to be deleted, got error: %v", obj, obj.GetName(), err)
+		}
+	}
+
+	// The KlusterletAddonConfig was not created by the operator, so it must be kept.
+	if err := cr.Get(context.TODO(), client.ObjectKeyFromObject(kac), kac); err != nil {
+		t.Errorf("expected KlusterletAddonConfig to be kept: %v", err)
+	}
+}
 func Test_Reconciler_CreateAutoImportSecret(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -600,6 +715,159 @@ func Test_Reconciler_EnsureAutoImportSecret(t *testing.T) {
 	}
 }
 
+func Test_Reconciler_EnsureImportCredentialAutoImportSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential *corev1.LocalObjectReference
+		mappings   []discovery.ImportCredentialMapping
+		data       map[string][]byte
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "should create kubeconfig auto-import Secret from referenced credential",
+			credential: &corev1.LocalObjectReference{Name: "import-creds"},
+			data:       map[string][]byte{"kubeconfig": []byte("fake-kubeconfig")},
+			want:       map[string]string{"kubeconfig": "fake-kubeconfig"},
+		},
+		{
+			name:     "should create token auto-import Secret from mapped credential",
+			mappings: []discovery.ImportCredentialMapping{{ClusterName: "foo", SecretName: "import-creds"}},
+			data: map[string][]byte{
+				"token": []byte("fake-token\n"), "server": []byte("https://api.foo.example.com:6443"),
+			},
+			want: map[string]string{"token": "fake-token", "server": "https://api.foo.example.com:6443"},
+		},
+		{
+			name:    "should fail without an import credential",
+			data:    map[string][]byte{"kubeconfig": []byte("fake-kubeconfig")},
+			wantErr: true,
+		},
+		{
+			name:       "should fail with a badly formatted credential",
+			credential: &corev1.LocalObjectReference{Name: "import-creds"},
+			data:       map[string][]byte{"token": []byte("fake-token")},
+			wantErr:    true,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &discovery.DiscoveryConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "bar"},
+				Spec:       discovery.DiscoveryConfigSpec{Credential: "admin", ImportCredentials: tt.mappings},
+			}
+			credential := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "import-creds", Namespace: "bar"},
+				Data:       tt.data,
+			}
+			dc := discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName:      "foo",
+					ImportCredential: tt.credential,
+					Type:             "OCP",
+				},
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config, credential).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := cr.EnsureImportCredentialAutoImportSecret(context.TODO(), dc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureImportCredentialAutoImportSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			secret := &corev1.Secret{}
+			if err := cr.Get(context.TODO(), types.NamespacedName{Name: "auto-import-secret",
+				Namespace: dc.Spec.DisplayName}, secret); err != nil {
+				t.Fatalf("failed to get auto import secret: %v", err)
+			}
+
+			if !reflect.DeepEqual(secret.StringData, tt.want) {
+				t.Errorf("auto import secret data = %v, want %v", secret.StringData, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Reconciler_EnsureImportCredentialAutoImportSecret_rotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		labels         map[string]string
+		wantKubeconfig string
+		wantEvent      bool
+	}{
+		{
+			name:           "should update the auto-import Secret created by discovery",
+			labels:         map[string]string{utils.LabelCreatedBy: "uid"},
+			wantKubeconfig: "rotated-kubeconfig",
+		},
+		{
+			name:           "should leave a pre-existing auto-import Secret unchanged",
+			wantKubeconfig: "fake-kubeconfig",
+			wantEvent:      true,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "import-creds", Namespace: "bar"},
+				Data:       map[string][]byte{"kubeconfig": []byte("rotated-kubeconfig")},
+			}
+			existing := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "auto-import-secret", Namespace: "foo", Labels: tt.labels},
+				Data:       map[string][]byte{"kubeconfig": []byte("fake-kubeconfig")},
+			}
+			dc := discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar", UID: "uid"},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName:      "foo",
+					ImportCredential: &corev1.LocalObjectReference{Name: "import-creds"},
+					Type:             "OCP",
+				},
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(credential, existing).Build(),
+				Recorder: recorder,
+			}
+
+			if _, err := cr.EnsureImportCredentialAutoImportSecret(context.TODO(), dc); err != nil {
+				t.Fatalf("EnsureImportCredentialAutoImportSecret() error = %v", err)
+			}
+
+			secret := &corev1.Secret{}
+			if err := cr.Get(context.TODO(), client.ObjectKeyFromObject(existing), secret); err != nil {
+				t.Fatalf("failed to get auto import secret: %v", err)
+			}
+			if got := string(secret.Data["kubeconfig"]); got != tt.wantKubeconfig {
+				t.Errorf("auto import secret kubeconfig = %q, want %q", got, tt.wantKubeconfig)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !tt.wantEvent || !strings.Contains(event, EventReasonAutoImportSecretUnmanaged) {
+					t.Errorf("recorded event %q, want event %v", event, tt.wantEvent)
+				}
+			default:
+				if tt.wantEvent {
+					t.Errorf("did not record a %s event", EventReasonAutoImportSecretUnmanaged)
+				}
+			}
+		})
+	}
+}
+
 func Test_Reconciler_EnsureCommonResources(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -693,6 +961,68 @@ func Test_Reconciler_EnsureCommonResources(t *testing.T) {
 	}
 }
 
+func Test_Reconciler_EnsureCommonResources_ManualImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		approved bool
+		want     bool
+	}{
+		{
+			name: "should not create the ManagedCluster before the import is approved",
+			want: false,
+		},
+		{
+			name:     "should create the ManagedCluster once the import is approved",
+			approved: true,
+			want:     true,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "bar",
+					Annotations: map[string]string{discovery.ImportStrategyAnnotation: discovery.ImportStrategyManual},
+				},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName:            "foo",
+					Type:                   "OCP",
+					ImportAsManagedCluster: true,
+					ImportApproved:         tt.approved,
+					ImportCredential:       &corev1.LocalObjectReference{Name: "foo-import"},
+				},
+			}
+			importCredential := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo-import", Namespace: "bar"},
+				Data:       map[string][]byte{"kubeconfig": []byte("fake-kubeconfig")},
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(importCredential).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			if _, err := cr.EnsureCredentialImport(context.TODO(), dc); err != nil {
+				t.Fatalf("failed to ensure credential import: %v", err)
+			}
+
+			err := cr.Get(context.TODO(), types.NamespacedName{Name: dc.Spec.DisplayName}, &clusterapiv1.ManagedCluster{})
+			if got := err == nil; got != tt.want {
+				t.Errorf("ManagedCluster exists = %v, want %v", got, tt.want)
+			}
+
+			secret := &corev1.Secret{}
+			nn := types.NamespacedName{Name: "auto-import-secret", Namespace: dc.Spec.DisplayName}
+			if err := cr.Get(context.TODO(), nn, secret); err != nil {
+				t.Errorf("failed to get auto import Secret: %v", err)
+			}
+		})
+	}
+}
+
 func Test_Reconciler_EnsureAddOnDeploymentConfig(t *testing.T) {
 	tests := []struct {
 		name string
@@ -723,7 +1053,11 @@ func Test_Reconciler_EnsureAddOnDeploymentConfig(t *testing.T) {
 				}
 			}()
 
-			if _, err := r.EnsureAddOnDeploymentConfig(context.TODO()); err != nil {
+			settings, err := r.getHostedAddOnSettings(context.TODO())
+			if err != nil {
+				t.Fatalf("failed to get hosted addon settings: %v", err)
+			}
+			if _, err := r.EnsureAddOnDeploymentConfig(context.TODO(), settings); err != nil {
 				t.Errorf("failed to create AddOnDeploymentConfig resource: %v", err)
 			}
 
@@ -872,7 +1206,7 @@ func Test_Reconciler_EnsureManagedClusterSetBinding(t *testing.T) {
 				}
 			}()
 
-			if _, err := r.EnsureManagedClusterSetBinding(context.TODO()); err != nil {
+			if _, err := r.EnsureManagedClusterSetBinding(context.TODO(), DefaultName, tt.nn.Namespace); err != nil {
 				t.Errorf("failed to create ManagedClusterSetBinding resource: %v", err)
 			}
 
@@ -1054,7 +1388,7 @@ func Test_Reconciler_EnsurePlacement(t *testing.T) {
 				}
 			}()
 
-			if _, err := r.EnsurePlacement(context.TODO()); err != nil {
+			if _, err := r.EnsurePlacement(context.TODO(), tt.nn); err != nil {
 				t.Errorf("failed to create Placement: %v", err)
 			}
 
@@ -1158,12 +1492,51 @@ func Test_Reconciler_EnsureROSA(t *testing.T) {
 
 func Test_Reconciler_buildStatusConditions(t *testing.T) {
 	now := metav1.Now()
+	trialEnd := metav1.NewTime(now.Add(24 * time.Hour))
 
 	tests := []struct {
 		name     string
 		dc       *discovery.DiscoveredCluster
+		config   *discovery.DiscoveryConfig
 		expected []discovery.DiscoveredClusterCondition
 	}{
+		{
+			name: "Stale cluster within the staleAfter threshold reports the OCM status separately",
+			dc: &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-cluster",
+					Namespace:  "default",
+					Generation: 1,
+				},
+				Spec: discovery.DiscoveredClusterSpec{
+					Status:            "Stale",
+					ActivityTimestamp: &now,
+				},
+			},
+			config: &discovery.DiscoveryConfig{
+				Spec: discovery.DiscoveryConfigSpec{StaleAfter: &metav1.Duration{Duration: time.Hour}},
+			},
+			expected: []discovery.DiscoveredClusterCondition{
+				{
+					Type:               discovery.ConditionAvailable,
+					Status:             metav1.ConditionTrue,
+					Reason:             discovery.ReasonActivityWithinThreshold,
+					ObservedGeneration: 1,
+				},
+				{
+					Type:               discovery.ConditionOCMAvailable,
+					Status:             metav1.ConditionFalse,
+					Reason:             discovery.ReasonStaleTelemetry,
+					ObservedGeneration: 1,
+				},
+				{
+					Type:               discovery.ConditionManaged,
+					Status:             metav1.ConditionFalse,
+					Reason:             discovery.ReasonNotImported,
+					ObservedGeneration: 1,
+				},
+			},
+		},
 		{
 			name: "Active cluster, not managed",
 			dc: &discovery.DiscoveredCluster{
@@ -1225,7 +1598,7 @@ func Test_Reconciler_buildStatusConditions(t *testing.T) {
 			},
 		},
 		{
-			name: "Active cluster without timestamp",
+			name: "Active cluster that never reported telemetry",
 			dc: &discovery.DiscoveredCluster{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "test-cluster",
@@ -1238,32 +1611,70 @@ func Test_Reconciler_buildStatusConditions(t *testing.T) {
 					IsManagedCluster:  false,
 				},
 			},
+			expected: []discovery.DiscoveredClusterCondition{
+				{
+					Type:               discovery.ConditionAvailable,
+					Status:             metav1.ConditionUnknown,
+					Reason:             discovery.ReasonNoTelemetry,
+					Message:            "Cluster has never reported telemetry. OCM status: Active",
+					ObservedGeneration: 1,
+				},
+				{
+					Type:               discovery.ConditionManaged,
+					Status:             metav1.ConditionFalse,
+					Reason:             discovery.ReasonNotImported,
+					Message:            "Cluster has not been imported",
+					ObservedGeneration: 1,
+				},
+			},
+		},
+		{
+			name: "Trial cluster ending within the expiry window",
+			dc: &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-cluster",
+					Namespace:  "default",
+					Generation: 1,
+				},
+				Spec: discovery.DiscoveredClusterSpec{
+					Status:            "Active",
+					ActivityTimestamp: &now,
+					TrialEndDate:      &trialEnd,
+				},
+			},
 			expected: []discovery.DiscoveredClusterCondition{
 				{
 					Type:               discovery.ConditionAvailable,
 					Status:             metav1.ConditionTrue,
 					Reason:             discovery.ReasonRecentTelemetry,
-					Message:            "Cluster is active",
 					ObservedGeneration: 1,
 				},
 				{
 					Type:               discovery.ConditionManaged,
 					Status:             metav1.ConditionFalse,
 					Reason:             discovery.ReasonNotImported,
-					Message:            "Cluster has not been imported",
+					ObservedGeneration: 1,
+				},
+				{
+					Type:               discovery.ConditionExpiringSoon,
+					Status:             metav1.ConditionTrue,
+					Reason:             discovery.ReasonTrialEndingSoon,
 					ObservedGeneration: 1,
 				},
 			},
 		},
 	}
 
+	registerScheme()
 	r := &DiscoveredClusterReconciler{
-		Client: fake.NewClientBuilder().Build(),
+		Client: fake.NewClientBuilder().
+			WithIndex(&discovery.DiscoveredCluster{}, managedClusterNameIndex, indexManagedClusterName).Build(),
+		Recorder: &record.FakeRecorder{},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			conditions := r.buildStatusConditions(context.TODO(), tt.dc)
+			conditions := r.buildStatusConditions(context.TODO(), tt.dc, tt.config)
 
 			if len(conditions) != len(tt.expected) {
 				t.Errorf("Expected %d conditions, got %d", len(tt.expected), len(conditions))
@@ -1293,67 +1704,388 @@ func Test_Reconciler_buildStatusConditions(t *testing.T) {
 	}
 }
 
-func Test_conditionEqual(t *testing.T) {
-	now := metav1.Now()
-	later := metav1.NewTime(now.Add(1000))
+func Test_buildImportedCondition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	managedCluster := func(conditions ...metav1.Condition) *clusterapiv1.ManagedCluster {
+		return &clusterapiv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Status:     clusterapiv1.ManagedClusterStatus{Conditions: conditions},
+		}
+	}
 
 	tests := []struct {
-		name     string
-		a        discovery.DiscoveredClusterCondition
-		b        discovery.DiscoveredClusterCondition
-		expected bool
+		name             string
+		mc               *clusterapiv1.ManagedCluster
+		awaitingApproval bool
+		wantStatus       metav1.ConditionStatus
+		wantReason       string
 	}{
 		{
-			name: "Identical conditions",
-			a: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionTrue,
-				Reason:             "RecentTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
-			},
-			b: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionTrue,
-				Reason:             "RecentTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
-			},
-			expected: true,
+			name:       "ManagedCluster not created",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonImportPending,
 		},
 		{
-			name: "Different Status",
-			a: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionTrue,
-				Reason:             "RecentTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
-			},
-			b: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionFalse,
-				Reason:             "RecentTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
-			},
-			expected: false,
+			name:             "Manual import awaiting approval",
+			awaitingApproval: true,
+			wantStatus:       metav1.ConditionFalse,
+			wantReason:       discovery.ReasonAwaitingApproval,
 		},
 		{
-			name: "Different Reason",
-			a: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionTrue,
-				Reason:             "RecentTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
-			},
-			b: discovery.DiscoveredClusterCondition{
-				Type:               "Available",
-				Status:             metav1.ConditionTrue,
-				Reason:             "StaleTelemetry",
-				Message:            "Cluster is active",
-				ObservedGeneration: 1,
+			name:       "ManagedCluster created",
+			mc:         managedCluster(),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonResourcesCreated,
+		},
+		{
+			name: "ManagedCluster accepted by the hub",
+			mc: managedCluster(metav1.Condition{
+				Type: clusterapiv1.ManagedClusterConditionHubAccepted, Status: metav1.ConditionTrue,
+			}),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonJoining,
+		},
+		{
+			name: "ManagedCluster joined and available",
+			mc: managedCluster(
+				metav1.Condition{Type: clusterapiv1.ManagedClusterConditionJoined, Status: metav1.ConditionTrue},
+				metav1.Condition{Type: clusterapiv1.ManagedClusterConditionAvailable, Status: metav1.ConditionTrue},
+			),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: discovery.ReasonManagedClusterAvailable,
+		},
+		{
+			name: "ManagedCluster import failed",
+			mc: managedCluster(metav1.Condition{
+				Type:   managedClusterConditionImportSucceeded,
+				Status: metav1.ConditionFalse,
+				Reason: managedClusterReasonImportFailed,
+			}),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonImportFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{Spec: discovery.DiscoveredClusterSpec{DisplayName: "foo"}}
+			got := buildImportedCondition(dc, tt.mc, tt.awaitingApproval, now)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("buildImportedCondition() = %s/%s, want %s/%s", got.Status, got.Reason, tt.wantStatus,
+					tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_buildExpiringSoonCondition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		trialEnd   time.Time
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "Trial ends after the expiry window",
+			trialEnd:   now.Add(30 * 24 * time.Hour),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonTrialActive,
+		},
+		{
+			name:       "Trial ends within the expiry window",
+			trialEnd:   now.Add(2 * 24 * time.Hour),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: discovery.ReasonTrialEndingSoon,
+		},
+		{
+			name:       "Trial has ended",
+			trialEnd:   now.Add(-time.Hour),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: discovery.ReasonTrialExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trialEnd := metav1.NewTime(tt.trialEnd)
+			dc := &discovery.DiscoveredCluster{Spec: discovery.DiscoveredClusterSpec{TrialEndDate: &trialEnd}}
+
+			got := buildExpiringSoonCondition(dc, discovery.DefaultTrialExpiryWindow, now)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("buildExpiringSoonCondition() = %s/%s, want %s/%s", got.Status, got.Reason,
+					tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_buildAvailableCondition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	hour := time.Hour
+
+	tests := []struct {
+		name       string
+		status     string
+		activity   *metav1.Time
+		staleAfter *time.Duration
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "Active in OCM without a threshold",
+			status:     "Active",
+			activity:   &metav1.Time{Time: now.Add(-2 * time.Hour)},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: discovery.ReasonRecentTelemetry,
+		},
+		{
+			name:       "Active in OCM but older than the threshold",
+			status:     "Active",
+			activity:   &metav1.Time{Time: now.Add(-2 * time.Hour)},
+			staleAfter: &hour,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonActivityThresholdExceeded,
+		},
+		{
+			name:       "Stale in OCM but within the threshold",
+			status:     "Stale",
+			activity:   &metav1.Time{Time: now.Add(-30 * time.Minute)},
+			staleAfter: &hour,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: discovery.ReasonActivityWithinThreshold,
+		},
+		{
+			name:       "Disconnected in OCM is not marked stale by the threshold",
+			status:     "Disconnected",
+			activity:   &metav1.Time{Time: now.Add(-2 * time.Hour)},
+			staleAfter: &hour,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonDisconnected,
+		},
+		{
+			name:       "Reserved in OCM is provisioning",
+			status:     "Reserved",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: discovery.ReasonProvisioning,
+		},
+		{
+			name:       "Unrecognized OCM status",
+			status:     "",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: discovery.ReasonUnknownStatus,
+		},
+		{
+			name:       "Threshold without activity falls back to OCM status",
+			status:     "Deprovisioned",
+			staleAfter: &hour,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: discovery.ReasonDeprovisioned,
+		},
+		{
+			name:       "Unreported Active cluster is Unknown",
+			status:     "Active",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: discovery.ReasonNoTelemetry,
+		},
+		{
+			name:       "Unreported Disconnected cluster is Unknown",
+			status:     "Disconnected",
+			staleAfter: &hour,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: discovery.ReasonNoTelemetry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				Spec: discovery.DiscoveredClusterSpec{Status: tt.status, ActivityTimestamp: tt.activity},
+			}
+			got := buildAvailableCondition(dc, tt.staleAfter, now)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("buildAvailableCondition() = %s/%s, want %s/%s", got.Status, got.Reason, tt.wantStatus,
+					tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_statusRefreshInterval(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		trialEnd   *metav1.Time
+		activity   *metav1.Time
+		staleAfter *metav1.Duration
+		want       time.Duration
+	}{
+		{
+			name: "No trial",
+			want: recon.ShortRefreshInterval,
+		},
+		{
+			name:       "Cluster becomes stale before the next refresh",
+			activity:   &metav1.Time{Time: now.Add(-time.Hour)},
+			staleAfter: &metav1.Duration{Duration: time.Hour + 3*time.Minute},
+			want:       3 * time.Minute,
+		},
+		{
+			name:       "Cluster is already stale",
+			activity:   &metav1.Time{Time: now.Add(-2 * time.Hour)},
+			staleAfter: &metav1.Duration{Duration: time.Hour},
+			want:       recon.ShortRefreshInterval,
+		},
+		{
+			name:     "Activity is ignored without a staleAfter threshold",
+			activity: &metav1.Time{Time: now.Add(-time.Hour)},
+			want:     recon.ShortRefreshInterval,
+		},
+		{
+			name:     "Expiry window starts before the next refresh",
+			trialEnd: &metav1.Time{Time: now.Add(discovery.DefaultTrialExpiryWindow + time.Minute)},
+			want:     time.Minute,
+		},
+		{
+			name:     "Trial ends before the next refresh",
+			trialEnd: &metav1.Time{Time: now.Add(2 * time.Minute)},
+			want:     2 * time.Minute,
+		},
+		{
+			name:     "Trial has ended",
+			trialEnd: &metav1.Time{Time: now.Add(-time.Hour)},
+			want:     recon.ShortRefreshInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				Spec: discovery.DiscoveredClusterSpec{
+					Status:            discovery.SubscriptionStatusActive,
+					TrialEndDate:      tt.trialEnd,
+					ActivityTimestamp: tt.activity,
+				},
+			}
+			config := &discovery.DiscoveryConfig{Spec: discovery.DiscoveryConfigSpec{StaleAfter: tt.staleAfter}}
+			if got := statusRefreshInterval(dc, config, now); got != tt.want {
+				t.Errorf("statusRefreshInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Reconciler_updateStatus_StaleEvent(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name       string
+		status     string
+		wantReason string
+	}{
+		{name: "Stale clusters record a Stale event", status: "Stale", wantReason: EventReasonStale},
+		{name: "Disconnected clusters record an Unavailable event", status: "Disconnected",
+			wantReason: EventReasonUnavailable},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "bar"},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName:       "stale",
+					Status:            tt.status,
+					ActivityTimestamp: &now,
+				},
+				Status: discovery.DiscoveredClusterStatus{
+					Conditions: []discovery.DiscoveredClusterCondition{
+						{Type: discovery.ConditionAvailable, Status: metav1.ConditionTrue},
+					},
+				},
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			sr := &DiscoveredClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(dc).
+					WithStatusSubresource(dc).Build(),
+				Recorder: recorder,
+			}
+
+			if err := sr.updateStatus(context.TODO(), dc, nil); err != nil {
+				t.Fatalf("failed to update status: %v", err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if want := corev1.EventTypeWarning + " " + tt.wantReason + " "; !strings.HasPrefix(event, want) {
+					t.Errorf("updateStatus() recorded event %q, want reason %s", event, tt.wantReason)
+				}
+			default:
+				t.Errorf("updateStatus() did not record a %s event", tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_conditionEqual(t *testing.T) {
+	now := metav1.Now()
+	later := metav1.NewTime(now.Add(1000))
+
+	tests := []struct {
+		name     string
+		a        discovery.DiscoveredClusterCondition
+		b        discovery.DiscoveredClusterCondition
+		expected bool
+	}{
+		{
+			name: "Identical conditions",
+			a: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "RecentTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
+			},
+			b: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "RecentTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
+			},
+			expected: true,
+		},
+		{
+			name: "Different Status",
+			a: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "RecentTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
+			},
+			b: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionFalse,
+				Reason:             "RecentTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
+			},
+			expected: false,
+		},
+		{
+			name: "Different Reason",
+			a: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "RecentTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
+			},
+			b: discovery.DiscoveredClusterCondition{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "StaleTelemetry",
+				Message:            "Cluster is active",
+				ObservedGeneration: 1,
 			},
 			expected: false,
 		},
@@ -1424,3 +2156,815 @@ func Test_conditionEqual(t *testing.T) {
 		})
 	}
 }
+
+func Test_Reconciler_applyImportPolicies(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := func(rateLimit *discovery.ImportRateLimit) *discovery.DiscoveryImportPolicy {
+		return &discovery.DiscoveryImportPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "rosa", Namespace: "bar"},
+			Spec: discovery.DiscoveryImportPolicySpec{
+				ClusterSelector: discovery.ImportPolicyClusterSelector{ClusterTypes: []string{"ROSA"}},
+				RateLimit:       rateLimit,
+			},
+		}
+	}
+	importedBy := func(name string, triggered time.Time) *discovery.DiscoveredCluster {
+		return &discovery.DiscoveredCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "bar",
+				Annotations: map[string]string{
+					discovery.ImportPolicyAnnotation:          "rosa",
+					discovery.ImportPolicyTimestampAnnotation: triggered.Format(time.RFC3339),
+				},
+			},
+			Spec: discovery.DiscoveredClusterSpec{DisplayName: name, Type: "ROSA", ImportAsManagedCluster: true},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		dcType     string
+		strategy   string
+		objs       []client.Object
+		wantImport bool
+		wantWait   time.Duration
+	}{
+		{
+			name:       "should set import intent of a selected cluster",
+			dcType:     "ROSA",
+			objs:       []client.Object{policy(nil)},
+			wantImport: true,
+		},
+		{
+			name:   "should ignore clusters that are not selected",
+			dcType: "OCP",
+			objs:   []client.Object{policy(nil)},
+		},
+		{
+			name:     "should ignore clusters with a Disabled import strategy",
+			dcType:   "ROSA",
+			strategy: discovery.ImportStrategyDisabled,
+			objs:     []client.Object{policy(nil)},
+		},
+		{
+			name:   "should delay the import once the rate limit is reached",
+			dcType: "ROSA",
+			objs: []client.Object{
+				policy(&discovery.ImportRateLimit{MaxImports: 1}),
+				importedBy("baz", now.Add(-20*time.Minute)),
+			},
+			wantWait: 40 * time.Minute,
+		},
+		{
+			name:   "should not count imports outside of the rate limit period",
+			dcType: "ROSA",
+			objs: []client.Object{
+				policy(&discovery.ImportRateLimit{MaxImports: 1}),
+				importedBy("baz", now.Add(-2*time.Hour)),
+			},
+			wantImport: true,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec:       discovery.DiscoveredClusterSpec{DisplayName: "foo", Type: tt.dcType},
+			}
+			strategy := tt.strategy
+			if strategy == "" {
+				strategy = discovery.ImportStrategyAutomatic
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(tt.objs, dc)...).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			wait, err := cr.applyImportPolicies(context.TODO(), dc, strategy, now)
+			if err != nil {
+				t.Fatalf("applyImportPolicies() error = %v", err)
+			}
+			if wait != tt.wantWait {
+				t.Errorf("applyImportPolicies() wait = %v, want %v", wait, tt.wantWait)
+			}
+
+			got := &discovery.DiscoveredCluster{}
+			if err := cr.Get(context.TODO(), types.NamespacedName{Name: "foo", Namespace: "bar"}, got); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if got.Spec.ImportAsManagedCluster != tt.wantImport {
+				t.Errorf("importAsManagedCluster = %v, want %v", got.Spec.ImportAsManagedCluster, tt.wantImport)
+			}
+			if policyName := got.GetAnnotations()[discovery.ImportPolicyAnnotation]; tt.wantImport && policyName != "rosa" {
+				t.Errorf("import policy annotation = %q, want %q", policyName, "rosa")
+			}
+		})
+	}
+}
+
+func Test_Reconciler_applyImportPolicies_staleCache(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := &discovery.DiscoveryImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "rosa", Namespace: "bar"},
+		Spec: discovery.DiscoveryImportPolicySpec{
+			ClusterSelector: discovery.ImportPolicyClusterSelector{ClusterTypes: []string{"ROSA"}},
+			RateLimit:       &discovery.ImportRateLimit{MaxImports: 1},
+		},
+	}
+	cluster := func(name string) *discovery.DiscoveredCluster {
+		return &discovery.DiscoveredCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bar"},
+			Spec:       discovery.DiscoveredClusterSpec{DisplayName: name, Type: "ROSA"},
+		}
+	}
+
+	registerScheme()
+	newClient := func() client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy.DeepCopy(), cluster("foo"), cluster("baz")).Build()
+	}
+	cr := &DiscoveredClusterReconciler{Client: newClient(), Recorder: &record.FakeRecorder{}}
+
+	if wait, err := cr.applyImportPolicies(context.TODO(), cluster("foo"), discovery.ImportStrategyAutomatic,
+		now); err != nil || wait != 0 {
+		t.Fatalf("applyImportPolicies() = %v, %v, want 0, nil", wait, err)
+	}
+
+	// A client that does not see the import of foo yet, like a lagging cache.
+	cr.Client = newClient()
+	wait, err := cr.applyImportPolicies(context.TODO(), cluster("baz"), discovery.ImportStrategyAutomatic, now)
+	if err != nil {
+		t.Fatalf("applyImportPolicies() error = %v", err)
+	}
+	if wait != time.Hour {
+		t.Errorf("applyImportPolicies() wait = %v, want %v", wait, time.Hour)
+	}
+}
+
+func Test_Reconciler_getManagedClusterSettings(t *testing.T) {
+	config := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "bar"},
+		Spec: discovery.DiscoveryConfigSpec{
+			Credential: "admin",
+			ManagedCluster: &discovery.ManagedClusterSettings{
+				ClusterSet:  "staging",
+				Labels:      map[string]string{"environment": "staging", "team": "platform"},
+				Annotations: map[string]string{"owner": "platform"},
+			},
+		},
+	}
+	policy := &discovery.DiscoveryImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "rosa", Namespace: "bar"},
+		Spec: discovery.DiscoveryImportPolicySpec{
+			ManagedClusterSet:    "production",
+			ManagedClusterLabels: map[string]string{"environment": "production"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		overrides      *discovery.ManagedClusterSettings
+		wantClusterSet string
+		wantLabels     map[string]string
+	}{
+		{
+			name:           "should use the settings of the DiscoveryConfig",
+			wantClusterSet: "staging",
+			wantLabels:     map[string]string{"environment": "staging", "team": "platform"},
+		},
+		{
+			name:           "should override the DiscoveryConfig with the import policy",
+			annotations:    map[string]string{discovery.ImportPolicyAnnotation: "rosa"},
+			wantClusterSet: "production",
+			wantLabels:     map[string]string{"environment": "production", "team": "platform"},
+		},
+		{
+			name:        "should override the import policy with the DiscoveredCluster",
+			annotations: map[string]string{discovery.ImportPolicyAnnotation: "rosa"},
+			overrides: &discovery.ManagedClusterSettings{
+				ClusterSet: "edge",
+				Labels:     map[string]string{"team": "edge"},
+			},
+			wantClusterSet: "edge",
+			wantLabels:     map[string]string{"environment": "production", "team": "edge"},
+		},
+	}
+
+	registerScheme()
+	cr := &DiscoveredClusterReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config, policy).Build(),
+		Recorder: &record.FakeRecorder{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar", Annotations: tt.annotations},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName: "foo", Type: "ROSA", ManagedCluster: tt.overrides,
+				},
+			}
+
+			got := cr.getManagedClusterSettings(context.TODO(), dc)
+			if got.ClusterSet != tt.wantClusterSet {
+				t.Errorf("ClusterSet = %q, want %q", got.ClusterSet, tt.wantClusterSet)
+			}
+			if !reflect.DeepEqual(got.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", got.Labels, tt.wantLabels)
+			}
+			if got.Annotations["owner"] != "platform" {
+				t.Errorf("Annotations = %v, want owner annotation of the DiscoveryConfig", got.Annotations)
+			}
+		})
+	}
+}
+
+func Test_applyManagedClusterSettings(t *testing.T) {
+	mc := r.CreateManagedCluster(types.NamespacedName{Name: "foo", Namespace: "bar"}, "ROSA")
+	applyManagedClusterSettings(mc, discovery.ManagedClusterSettings{
+		ClusterSet: "production",
+		Labels: map[string]string{
+			"environment": "production", utils.LabelCloud: "Amazon", utils.LabelName: "bar",
+		},
+		Annotations: map[string]string{"owner": "platform", utils.AnnotationCreatedVia: "user"},
+	})
+
+	wantLabels := map[string]string{
+		utils.LabelName:       "foo",
+		utils.LabelCloud:      "Amazon",
+		utils.LabelVendor:     discovery.AutoDetectLabels,
+		utils.LabelClusterSet: "production",
+		"environment":         "production",
+	}
+	if !reflect.DeepEqual(mc.Labels, wantLabels) {
+		t.Errorf("Labels = %v, want %v", mc.Labels, wantLabels)
+	}
+
+	wantAnnotations := map[string]string{utils.AnnotationCreatedVia: "discovery", "owner": "platform"}
+	if !reflect.DeepEqual(mc.Annotations, wantAnnotations) {
+		t.Errorf("Annotations = %v, want %v", mc.Annotations, wantAnnotations)
+	}
+}
+
+func Test_Reconciler_EnsureManagedCluster_KlusterletConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		objs       []client.Object
+		wantErr    bool
+		wantReason string
+	}{
+		{
+			name: "should stamp the KlusterletConfig onto the ManagedCluster",
+			objs: []client.Object{
+				&klusterletconfigv1alpha1.KlusterletConfig{ObjectMeta: metav1.ObjectMeta{Name: "proxy"}},
+			},
+			wantReason: discovery.ReasonResourcesCreated,
+		},
+		{
+			name:       "should fail the import when the KlusterletConfig does not exist",
+			wantErr:    true,
+			wantReason: discovery.ReasonKlusterletConfigNotFound,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+				Spec: discovery.DiscoveredClusterSpec{
+					DisplayName:            "foo",
+					Type:                   "ROSA",
+					ImportAsManagedCluster: true,
+					ManagedCluster:         &discovery.ManagedClusterSettings{KlusterletConfig: "proxy"},
+				},
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.objs...).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := cr.EnsureManagedCluster(context.TODO(), *dc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureManagedCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			mc := &clusterapiv1.ManagedCluster{}
+			err = cr.Get(context.TODO(), types.NamespacedName{Name: "foo"}, mc)
+			if tt.wantErr && !apierrors.IsNotFound(err) {
+				t.Errorf("expected no ManagedCluster, got error %v", err)
+			}
+			if !tt.wantErr && mc.Annotations[utils.AnnotationKlusterletConfig] != "proxy" {
+				t.Errorf("KlusterletConfig annotation = %q, want %q", mc.Annotations[utils.AnnotationKlusterletConfig],
+					"proxy")
+			}
+
+			conditions := cr.buildStatusConditions(context.TODO(), dc, nil)
+			imported := findCondition(conditions, discovery.ConditionImported)
+			if imported == nil || imported.Reason != tt.wantReason {
+				t.Errorf("Imported condition = %v, want reason %s", imported, tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_Reconciler_syncKlusterletAddonConfig(t *testing.T) {
+	disabled := false
+	config := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "bar"},
+		Spec: discovery.DiscoveryConfigSpec{
+			Credential: "admin",
+			KlusterletAddonConfigTemplate: &discovery.KlusterletAddonConfigTemplate{
+				SearchCollector: &disabled,
+				ClusterLabels:   map[string]string{utils.LabelCloud: "Amazon", utils.LabelName: "bar"},
+			},
+		},
+	}
+	dc := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar", UID: "12345"},
+		Spec:       discovery.DiscoveredClusterSpec{DisplayName: "foo", Type: "ROSA", IsManagedCluster: true},
+	}
+
+	tests := []struct {
+		name          string
+		createdBy     bool
+		wantSearch    bool
+		wantCloudName string
+	}{
+		{
+			name:          "should reconcile the template onto KlusterletAddonConfigs created by the operator",
+			createdBy:     true,
+			wantSearch:    false,
+			wantCloudName: "Amazon",
+		},
+		{
+			name:          "should leave other KlusterletAddonConfigs unchanged",
+			wantSearch:    true,
+			wantCloudName: discovery.AutoDetectLabels,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nn := types.NamespacedName{Name: "foo", Namespace: "foo"}
+			kac := r.CreateKlusterletAddonConfig(nn)
+			if tt.createdBy {
+				setCreatedBy(kac, dc)
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config, kac).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			if err := cr.syncKlusterletAddonConfig(context.TODO(), dc); err != nil {
+				t.Fatalf("syncKlusterletAddonConfig() error = %v", err)
+			}
+
+			got := &agentv1.KlusterletAddonConfig{}
+			if err := cr.Get(context.TODO(), nn, got); err != nil {
+				t.Fatalf("failed to get KlusterletAddonConfig: %v", err)
+			}
+			if got.Spec.SearchCollectorConfig.Enabled != tt.wantSearch {
+				t.Errorf("search collector enabled = %v, want %v", got.Spec.SearchCollectorConfig.Enabled, tt.wantSearch)
+			}
+			if !got.Spec.PolicyController.Enabled {
+				t.Errorf("policy controller should stay enabled")
+			}
+			if got.Spec.ClusterLabels[utils.LabelCloud] != tt.wantCloudName {
+				t.Errorf("cloud label = %q, want %q", got.Spec.ClusterLabels[utils.LabelCloud], tt.wantCloudName)
+			}
+			if got.Spec.ClusterLabels[utils.LabelName] != "foo" {
+				t.Errorf("name label = %q, want %q", got.Spec.ClusterLabels[utils.LabelName], "foo")
+			}
+		})
+	}
+}
+
+func Test_Reconciler_resolveManagedClusterName(t *testing.T) {
+	otherCluster := &clusterapiv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: map[string]string{managedClusterIDLabel: "other-id"}},
+	}
+	sameCluster := &clusterapiv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: map[string]string{managedClusterIDLabel: "1a2b3c4d5e6f"}},
+	}
+	otherDiscoveredCluster := &discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "bar", UID: "other-uid"},
+		Spec:       discovery.DiscoveredClusterSpec{Name: "other-id", DisplayName: "foo"},
+		Status:     discovery.DiscoveredClusterStatus{ManagedClusterName: "foo"},
+	}
+
+	createdCluster := &clusterapiv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-1a2b3c4d", Labels: map[string]string{utils.LabelCreatedBy: "uid"}},
+	}
+
+	tests := []struct {
+		name        string
+		displayName string
+		dcType      string
+		recorded    string
+		annotations map[string]string
+		config      *discovery.DiscoveryConfig
+		objs        []client.Object
+		wantName    string
+		wantReason  string
+	}{
+		{
+			name:        "should use the display name when it is available",
+			displayName: "foo",
+			wantName:    "foo",
+			wantReason:  discovery.ReasonNameAvailable,
+		},
+		{
+			name:        "should use the ManagedCluster of the same cluster",
+			displayName: "foo",
+			objs:        []client.Object{sameCluster},
+			wantName:    "foo",
+			wantReason:  discovery.ReasonNameAvailable,
+		},
+		{
+			name:        "should suffix the name used by another ManagedCluster",
+			displayName: "foo",
+			objs:        []client.Object{otherCluster},
+			wantName:    "foo-1a2b3c4d",
+			wantReason:  discovery.ReasonNameSuffixed,
+		},
+		{
+			name:        "should suffix the name claimed by another DiscoveredCluster",
+			displayName: "foo",
+			objs:        []client.Object{otherDiscoveredCluster},
+			wantName:    "foo-1a2b3c4d",
+			wantReason:  discovery.ReasonNameSuffixed,
+		},
+		{
+			name:        "should not suffix MultiClusterEngineHCP clusters",
+			displayName: "foo",
+			dcType:      "MultiClusterEngineHCP",
+			objs:        []client.Object{otherCluster},
+			wantReason:  discovery.ReasonNameUnavailable,
+		},
+		{
+			name:        "should not suffix clusters that opted out",
+			displayName: "foo",
+			annotations: map[string]string{discovery.ManagedClusterNameSuffixAnnotation: "false"},
+			objs:        []client.Object{otherCluster},
+			wantReason:  discovery.ReasonNameUnavailable,
+		},
+		{
+			name:        "should not suffix clusters whose DiscoveryConfig opted out",
+			displayName: "foo",
+			config: &discovery.DiscoveryConfig{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{discovery.ManagedClusterNameSuffixAnnotation: "false"},
+			}},
+			objs:       []client.Object{otherCluster},
+			wantReason: discovery.ReasonNameUnavailable,
+		},
+		{
+			name:        "should keep the recorded name of the ManagedCluster created for the cluster",
+			displayName: "foo",
+			recorded:    "foo-1a2b3c4d",
+			objs:        []client.Object{createdCluster},
+			wantName:    "foo-1a2b3c4d",
+			wantReason:  discovery.ReasonNameSuffixed,
+		},
+		{
+			name:        "should sanitize and truncate the display name",
+			displayName: "My_Cluster." + strings.Repeat("a", 70),
+			wantName:    "my-cluster-" + strings.Repeat("a", 52),
+			wantReason:  discovery.ReasonNameAvailable,
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "1a2b3c4d5e6f",
+					Namespace:   "bar",
+					UID:         "uid",
+					Annotations: tt.annotations,
+				},
+				Spec: discovery.DiscoveredClusterSpec{
+					Name:           "1a2b3c4d5e6f",
+					DisplayName:    tt.displayName,
+					RHOCMClusterID: "1a2b3c4d5e6f",
+					Type:           tt.dcType,
+				},
+				Status: discovery.DiscoveredClusterStatus{ManagedClusterName: tt.recorded},
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(tt.objs, dc)...).
+					WithIndex(&discovery.DiscoveredCluster{}, managedClusterNameIndex, indexManagedClusterName).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			name, reason, err := cr.resolveManagedClusterName(context.TODO(), *dc, tt.config)
+			if err != nil {
+				t.Fatalf("resolveManagedClusterName() error = %v", err)
+			}
+			if name != tt.wantName || reason != tt.wantReason {
+				t.Errorf("resolveManagedClusterName() = (%q, %q), want (%q, %q)", name, reason, tt.wantName,
+					tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_Reconciler_ensureHostedAddOns(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "operator")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	config := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "bar"},
+		Spec: discovery.DiscoveryConfigSpec{
+			Credential: "admin",
+			HostedAddOns: &discovery.HostedAddOnSettings{
+				AddOns:                []string{"cluster-proxy"},
+				PlacementName:         "hosted",
+				PlacementNamespace:    "addons",
+				AgentInstallNamespace: "hosted-agents",
+				NodeSelector:          map[string]string{"node-role.kubernetes.io/infra": ""},
+			},
+		},
+	}
+	// DiscoveryConfigs that do not set the hosted addons use the settings of the other DiscoveryConfigs.
+	otherConfig := &discovery.DiscoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.DefaultDiscoveryConfigName, Namespace: "baz"},
+		Spec:       discovery.DiscoveryConfigSpec{Credential: "admin"},
+	}
+	adc := r.CreateAddOnDeploymentConfig(types.NamespacedName{Name: AddOnDeploymentConfigName, Namespace: "operator"})
+	cma := func(name string) *addonv1alpha1.ClusterManagementAddOn {
+		return &addonv1alpha1.ClusterManagementAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: addonv1alpha1.ClusterManagementAddOnSpec{
+				InstallStrategy: addonv1alpha1.InstallStrategy{
+					Type: "Placements",
+					Placements: []addonv1alpha1.PlacementStrategy{{
+						PlacementRef: addonv1alpha1.PlacementRef{Name: DefaultName, Namespace: "operator"},
+						Configs: []addonv1alpha1.AddOnConfig{{
+							ConfigReferent: addonv1alpha1.ConfigReferent{
+								Name:      AddOnDeploymentConfigName,
+								Namespace: "operator",
+							},
+							ConfigGroupResource: addonv1alpha1.ConfigGroupResource{
+								Group:    "addon.open-cluster-management.io",
+								Resource: "addondeploymentconfigs",
+							},
+						}},
+					}},
+				},
+			},
+		}
+	}
+	dc := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"},
+		Spec:       discovery.DiscoveredClusterSpec{DisplayName: "foo", Type: "MultiClusterEngineHCP"},
+	}
+
+	registerScheme()
+	cr := &DiscoveredClusterReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(config, otherConfig, adc, cma("cluster-proxy"), cma("work-manager")).Build(),
+		Recorder: &record.FakeRecorder{},
+	}
+
+	if _, err := cr.ensureHostedAddOns(context.TODO(), dc); err != nil {
+		t.Fatalf("ensureHostedAddOns() error = %v", err)
+	}
+
+	if err := cr.Get(context.TODO(), types.NamespacedName{Name: "hosted", Namespace: "addons"},
+		&clusterapiv1beta1.Placement{}); err != nil {
+		t.Errorf("failed to get Placement: %v", err)
+	}
+
+	if err := cr.Get(context.TODO(), types.NamespacedName{Name: DefaultName, Namespace: "addons"},
+		&clusterapiv1beta2.ManagedClusterSetBinding{}); err != nil {
+		t.Errorf("failed to get ManagedClusterSetBinding: %v", err)
+	}
+
+	gotADC := &addonv1alpha1.AddOnDeploymentConfig{}
+	if err := cr.Get(context.TODO(), client.ObjectKeyFromObject(adc), gotADC); err != nil {
+		t.Fatalf("failed to get AddOnDeploymentConfig: %v", err)
+	}
+	if gotADC.Spec.AgentInstallNamespace != "hosted-agents" {
+		t.Errorf("agentInstallNamespace = %q, want %q", gotADC.Spec.AgentInstallNamespace, "hosted-agents")
+	}
+	if gotADC.Spec.NodePlacement == nil || len(gotADC.Spec.NodePlacement.NodeSelector) != 1 {
+		t.Errorf("nodePlacement = %v, want the configured node selector", gotADC.Spec.NodePlacement)
+	}
+
+	clusterProxy := &addonv1alpha1.ClusterManagementAddOn{}
+	if err := cr.Get(context.TODO(), types.NamespacedName{Name: "cluster-proxy"}, clusterProxy); err != nil {
+		t.Fatalf("failed to get ClusterManagementAddOn: %v", err)
+	}
+	placements := clusterProxy.Spec.InstallStrategy.Placements
+	if len(placements) != 1 || placements[0].Name != "hosted" || placements[0].Namespace != "addons" {
+		t.Errorf("cluster-proxy placements = %v, want only hosted/addons", placements)
+	}
+
+	workManager := &addonv1alpha1.ClusterManagementAddOn{}
+	if err := cr.Get(context.TODO(), types.NamespacedName{Name: "work-manager"}, workManager); err != nil {
+		t.Fatalf("failed to get ClusterManagementAddOn: %v", err)
+	}
+	if placements := workManager.Spec.InstallStrategy.Placements; len(placements) != 0 {
+		t.Errorf("work-manager placements = %v, want none", placements)
+	}
+
+	otherDC := discovery.DiscoveredCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "qux", Namespace: "baz"},
+		Spec:       discovery.DiscoveredClusterSpec{DisplayName: "qux", Type: "MultiClusterEngineHCP"},
+	}
+	if _, err := cr.ensureHostedAddOns(context.TODO(), otherDC); err != nil {
+		t.Fatalf("ensureHostedAddOns() error = %v", err)
+	}
+	if err := cr.Get(context.TODO(), client.ObjectKeyFromObject(adc), gotADC); err != nil {
+		t.Fatalf("failed to get AddOnDeploymentConfig: %v", err)
+	}
+	if gotADC.Spec.AgentInstallNamespace != "hosted-agents" {
+		t.Errorf("agentInstallNamespace = %q, want the settings of the other namespace",
+			gotADC.Spec.AgentInstallNamespace)
+	}
+}
+
+func Test_Reconciler_handleReimport(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	requestedAt := now.Add(-time.Minute).Format(time.RFC3339)
+	deletedCluster := &clusterapiv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+
+	tests := []struct {
+		name       string
+		reimport   string
+		history    []discovery.ReimportRecord
+		restarted  bool
+		objs       []client.Object
+		wantImport bool
+		wantWait   time.Duration
+	}{
+		{
+			name: "should not import clusters without a reimport request",
+		},
+		{
+			name:     "should wait for the previous ManagedCluster to be deleted",
+			reimport: requestedAt,
+			objs:     []client.Object{deletedCluster},
+			wantWait: recon.WarningRefreshInterval,
+		},
+		{
+			name:       "should restart the import once the previous ManagedCluster is deleted",
+			reimport:   requestedAt,
+			wantImport: true,
+		},
+		{
+			name:       "should record reimport requests whose import was already restarted",
+			reimport:   requestedAt,
+			restarted:  true,
+			objs:       []client.Object{deletedCluster},
+			wantImport: true,
+		},
+		{
+			name:     "should ignore reimport requests that were already processed",
+			reimport: requestedAt,
+			history: []discovery.ReimportRecord{
+				{RequestedAt: metav1.NewTime(now.Add(-time.Minute)), StartedAt: metav1.NewTime(now)},
+			},
+		},
+		{
+			name:     "should ignore invalid reimport requests",
+			reimport: "yesterday",
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "bar",
+					Annotations: map[string]string{utils.AnnotationPreviouslyAutoImported: "true"},
+				},
+				Spec:   discovery.DiscoveredClusterSpec{DisplayName: "foo", Type: "ROSA"},
+				Status: discovery.DiscoveredClusterStatus{ReimportHistory: tt.history},
+			}
+			if tt.reimport != "" {
+				dc.Annotations[discovery.ReimportAnnotation] = tt.reimport
+			}
+			if tt.restarted {
+				delete(dc.Annotations, utils.AnnotationPreviouslyAutoImported)
+				dc.Spec.ImportAsManagedCluster = true
+			}
+
+			cr := &DiscoveredClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(tt.objs, dc)...).
+					WithStatusSubresource(dc).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			wait, err := cr.handleReimport(context.TODO(), dc, discovery.ImportStrategyAutomatic, now)
+			if err != nil {
+				t.Fatalf("handleReimport() error = %v", err)
+			}
+			if wait != tt.wantWait {
+				t.Errorf("handleReimport() wait = %v, want %v", wait, tt.wantWait)
+			}
+
+			got := &discovery.DiscoveredCluster{}
+			if err := cr.Get(context.TODO(), types.NamespacedName{Name: "foo", Namespace: "bar"}, got); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if got.Spec.ImportAsManagedCluster != tt.wantImport {
+				t.Errorf("importAsManagedCluster = %v, want %v", got.Spec.ImportAsManagedCluster, tt.wantImport)
+			}
+			if guarded := utils.IsAnnotationTrue(got, utils.AnnotationPreviouslyAutoImported); guarded == tt.wantImport {
+				t.Errorf("previously auto imported annotation set = %v, want %v", guarded, !tt.wantImport)
+			}
+			if wantHistory := len(tt.history) + map[bool]int{true: 1}[tt.wantImport]; len(got.Status.ReimportHistory) !=
+				wantHistory {
+				t.Errorf("reimport history = %v, want %d entries", got.Status.ReimportHistory, wantHistory)
+			}
+		})
+	}
+}
+
+func Test_ImportQueue_Admit(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newDC := func(name string, priority string) *discovery.DiscoveredCluster {
+		dc := &discovery.DiscoveredCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bar"}}
+		if priority != "" {
+			dc.Annotations = map[string]string{discovery.ImportPriorityAnnotation: priority}
+		}
+		return dc
+	}
+
+	t.Run("should limit the number of concurrent imports", func(t *testing.T) {
+		q := NewImportQueue(1, 0, ImportQueueOrderingFIFO)
+		a, b := newDC("a", ""), newDC("b", "")
+
+		if admitted, _, _ := q.Admit(a, now); !admitted {
+			t.Errorf("Admit(a) = false, want true")
+		}
+		if admitted, position, wait := q.Admit(b, now); admitted || position != 1 || wait != importQueueRetryInterval {
+			t.Errorf("Admit(b) = (%v, %d, %v), want (false, 1, %v)", admitted, position, wait,
+				importQueueRetryInterval)
+		}
+
+		q.Done(client.ObjectKeyFromObject(a))
+		if admitted, _, _ := q.Admit(b, now); !admitted {
+			t.Errorf("Admit(b) after Done(a) = false, want true")
+		}
+	})
+
+	t.Run("should free the slots of imports that timed out", func(t *testing.T) {
+		q := NewImportQueue(1, 0, ImportQueueOrderingFIFO)
+		q.Admit(newDC("a", ""), now)
+		if admitted, _, _ := q.Admit(newDC("b", ""), now.Add(importQueueSlotTimeout+time.Second)); !admitted {
+			t.Errorf("Admit(b) = false, want true")
+		}
+	})
+
+	t.Run("should limit the number of imports per minute", func(t *testing.T) {
+		q := NewImportQueue(0, 2, ImportQueueOrderingFIFO)
+		for _, name := range []string{"a", "b"} {
+			if admitted, _, _ := q.Admit(newDC(name, ""), now); !admitted {
+				t.Errorf("Admit(%s) = false, want true", name)
+			}
+		}
+		if admitted, _, wait := q.Admit(newDC("c", ""), now); admitted || wait != 30*time.Second {
+			t.Errorf("Admit(c) = (%v, %v), want (false, 30s)", admitted, wait)
+		}
+		if admitted, _, _ := q.Admit(newDC("c", ""), now.Add(30*time.Second)); !admitted {
+			t.Errorf("Admit(c) after 30s = false, want true")
+		}
+	})
+
+	t.Run("should order queued imports", func(t *testing.T) {
+		for _, tt := range []struct {
+			ordering string
+			want     map[string]int
+		}{
+			{ordering: ImportQueueOrderingFIFO, want: map[string]int{"low": 1, "high": 2}},
+			{ordering: ImportQueueOrderingPriority, want: map[string]int{"low": 2, "high": 1}},
+		} {
+			q := NewImportQueue(1, 0, tt.ordering)
+			q.Admit(newDC("running", ""), now)
+			q.Admit(newDC("low", "1"), now)
+			q.Admit(newDC("high", "10"), now)
+
+			for name, want := range tt.want {
+				if got, length := q.Position(types.NamespacedName{Name: name, Namespace: "bar"}); got != want ||
+					length != 2 {
+					t.Errorf("%s: Position(%s) = (%d, %d), want (%d, 2)", tt.ordering, name, got, length, want)
+				}
+			}
+		}
+	})
+}
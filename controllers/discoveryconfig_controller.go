@@ -30,7 +30,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -38,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ref "k8s.io/client-go/tools/reference"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -47,14 +48,11 @@ import (
 	discovery "github.com/stolostron/discovery/api/v1"
 	"github.com/stolostron/discovery/pkg/ocm"
 	"github.com/stolostron/discovery/pkg/ocm/auth"
+	"github.com/stolostron/discovery/pkg/platform"
 	recon "github.com/stolostron/discovery/util/reconciler"
 	corev1 "k8s.io/api/core/v1"
 )
 
-const (
-	DefaultDiscoveryConfigName = "discovery"
-)
-
 var logf = log.Log.WithName("reconcile")
 
 var (
@@ -72,7 +70,11 @@ var mockDiscoveredCluster = func() ([]discovery.DiscoveredCluster, error) {
 // DiscoveryConfigReconciler reconciles a DiscoveryConfig object
 type DiscoveryConfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Capabilities are the optional APIs served by the hub. Every API is assumed to be served when it is nil.
+	Capabilities *platform.Capabilities
 }
 
 // +kubebuilder:rbac:groups="",resources=namespaces;secrets,verbs=create;get;list;update;watch
@@ -80,6 +82,7 @@ type DiscoveryConfigReconciler struct {
 // +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveryconfigs/finalizers,verbs=get;patch;update
 // +kubebuilder:rbac:groups=discovery.open-cluster-management.io,resources=discoveryconfigs/status,verbs=get;patch;update
 // +kubebuilder:rbac:groups=config.openshift.io,resources=apiservers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *DiscoveryConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logf.Info("Reconciling DiscoveryConfig", "Name", req.Name, "Namespace", req.Namespace)
@@ -136,6 +139,8 @@ func (r *DiscoveryConfigReconciler) updateDiscoveredClusters(ctx context.Context
 
 		if apierrors.IsNotFound(err) {
 			logf.Info("Secret does not exist. Deleting all clusters.", "Secret", secretName)
+			r.Recorder.Eventf(config, corev1.EventTypeWarning, EventReasonCredentialInvalid,
+				"Credential secret %s not found", secretName)
 			return r.deleteAllClusters(ctx, config)
 		}
 
@@ -151,9 +156,11 @@ func (r *DiscoveryConfigReconciler) updateDiscoveredClusters(ctx context.Context
 	}
 
 	// Parse user token from ocm secret.
-	authRequest, err := parseSecretForAuth(ocmSecret)
+	authRequest, err := auth.ParseSecretForAuth(ocmSecret)
 	if err != nil {
 		logf.Error(err, "Error parsing token from secret. Deleting all clusters.", "Secret", ocmSecret.GetName())
+		r.Recorder.Eventf(config, corev1.EventTypeWarning, EventReasonCredentialInvalid,
+			"Failed to parse credential secret %s: %v", ocmSecret.GetName(), err)
 		return r.deleteAllClusters(ctx, config)
 	}
 
@@ -179,6 +186,8 @@ func (r *DiscoveryConfigReconciler) updateDiscoveredClusters(ctx context.Context
 	if err != nil {
 		if ocm.IsUnrecoverable(err) || ocm.IsUnauthorizedClient(err) || ocm.IsInvalidClient(err) {
 			logf.Info("Error encountered. Cleaning up clusters.", "Error", err.Error())
+			r.Recorder.Eventf(config, corev1.EventTypeWarning, EventReasonCredentialInvalid,
+				"OCM rejected the credential in secret %s: %v", secretName, err)
 			return r.deleteAllClusters(ctx, config)
 		}
 		return err
@@ -276,7 +285,7 @@ func (r *DiscoveryConfigReconciler) updateDiscoveredClusters(ctx context.Context
 
 	// Everything remaining in existing should be deleted
 	for _, c := range existing {
-		err := r.deleteCluster(ctx, c)
+		err := r.deleteCluster(ctx, config, c)
 		if err != nil {
 			return err
 		}
@@ -290,63 +299,13 @@ ValidateDiscoveryConfigName validates the name of the DiscoveryConfig resource.
 It ensures that the provided name matches the DefaultDiscoveryConfigName.
 */
 func (r *DiscoveryConfigReconciler) validateDiscoveryConfigName(reqName string) error {
-	if reqName != DefaultDiscoveryConfigName {
+	if reqName != discovery.DefaultDiscoveryConfigName {
 		return fmt.Errorf("invalid DiscoveryConfig resource name '%s', it must be '%s'",
-			reqName, DefaultDiscoveryConfigName)
+			reqName, discovery.DefaultDiscoveryConfigName)
 	}
 	return nil
 }
 
-/*
-parseSecretForAuth parses the given Secret to retrieve authentication credentials.
-Depending on the "auth_method" field in the secret, it returns either service account credentials
-(client_id, client_secret) or an offline token (ocmAPIToken). If "auth_method" is not set, it
-defaults to using the "offline-token" method. Returns an error if the expected fields are missing.
-*/
-func parseSecretForAuth(secret *corev1.Secret) (auth.AuthRequest, error) {
-	// Set the default auth_method to "offline-token"
-	authMethod := "offline-token"
-
-	// Check if the "auth_method" key is present in the Secret data
-	if method, found := secret.Data["auth_method"]; found {
-		authMethod = string(method)
-	}
-
-	credentials := auth.AuthRequest{
-		AuthMethod: strings.TrimSuffix(string(authMethod), "\n"), // Set the authentication method
-	}
-
-	// Handle based on the "auth_method" value
-	switch credentials.AuthMethod {
-	case "service-account":
-		// Retrieve client_id and client_secret for service-account auth method
-		clientID, idOk := secret.Data["client_id"]
-		clientSecret, secretOk := secret.Data["client_secret"]
-
-		if !idOk || !secretOk {
-			return credentials, fmt.Errorf(
-				"%s: bad format: secret must contain client_id and client_secret", secret.Name)
-		}
-
-		credentials.ID = strings.TrimSuffix(string(clientID), "\n")
-		credentials.Secret = strings.TrimSuffix(string(clientSecret), "\n")
-
-	case "offline-token":
-		// Retrive ocmAPIToken for offline-token auth method
-		token, tokenOk := secret.Data["ocmAPIToken"]
-		if !tokenOk {
-			return credentials, fmt.Errorf("%s: bad format: secret must contain ocmAPIToken", secret.Name)
-		}
-
-		credentials.Token = strings.TrimSuffix(string(token), "\n")
-
-	default:
-		return credentials, fmt.Errorf("%s: bad format: unsupported auth_method:  %s", secret.Name, authMethod)
-	}
-
-	return credentials, nil
-}
-
 func (r *DiscoveryConfigReconciler) AddDefaultAuthMethodToSecret(ctx context.Context, secret *corev1.Secret) error {
 	// Set the default auth_method to "offline-token"
 	secret.Data["auth_method"] = []byte("offline-token")
@@ -384,6 +343,11 @@ func assignManagedStatus(discovered map[string]discovery.DiscoveredCluster, mana
 func (r *DiscoveryConfigReconciler) getManagedClusters() ([]metav1.PartialObjectMetadata, error) {
 	ctx := context.Background()
 
+	// Without the ManagedCluster API, no discovered cluster can be managed.
+	if !r.Capabilities.Has(platform.ManagedCluster) {
+		return nil, nil
+	}
+
 	managedMeta := &metav1.PartialObjectMetadataList{TypeMeta: metav1.TypeMeta{Kind: "ManagedClusterList", APIVersion: "cluster.open-cluster-management.io/v1"}}
 	if err := r.Client.List(ctx, managedMeta); client.IgnoreNotFound(err) != nil {
 		return nil, errors.Wrapf(err, "error listing managed clusters")
@@ -414,25 +378,36 @@ func (r *DiscoveryConfigReconciler) applyCluster(ctx context.Context, config *di
 	}
 
 	/*
-		In MCE 2.6, we introduced a feature that allows discovered cluster resources to be automatically imported when
-		the importAsManagedCluster field is set to true. Since this field is not included in the subscription data when
-		querying OCM, we must ensure that the discovered cluster resource retains the importAsManagedCluster status
-		from the original discovered cluster. This is necessary because the field is internally defined and not part
-		of OCM's data.
-
-		To ensure proper functionality, we need to pass the value before determining if the cluster requires an update.
-		Otherwise, the cluster will always be marked for update, as the field is not present in the most recent
-		discovered cluster specification.
+		The syncer only owns the fields derived from OCM. User intent such as importAsManagedCluster is never part of
+		the apply configuration, so it does not need to be carried over from the existing cluster before comparing.
 	*/
-	dc.Spec.ImportAsManagedCluster = current.Spec.ImportAsManagedCluster
+	if !dc.SourceEqual(current) {
+		if err := migrateSourceFields(ctx, r.Client, current, dc); err != nil {
+			return err
+		}
+		if err := applySourceFields(ctx, r.Client, config, dc.DeepCopy()); err != nil {
+			return err
+		}
+		logf.Info("Updated cluster", "Name", dc.Name)
 
-	if dc.Equal(current) {
-		// Discovered cluster has not changed
-		return nil
+		if current.Spec.OpenshiftVersion != "" && dc.Spec.OpenshiftVersion != "" &&
+			current.Spec.OpenshiftVersion != dc.Spec.OpenshiftVersion {
+			r.Recorder.Eventf(&current, corev1.EventTypeNormal, EventReasonVersionChanged,
+				"OpenShift version changed from %s to %s", current.Spec.OpenshiftVersion, dc.Spec.OpenshiftVersion)
+		}
 	}
 
-	// Cluster needs to be updated
-	return r.updateCluster(ctx, dc, current)
+	if managedStatusChanged(current, dc.Spec.IsManagedCluster) {
+		if err := applyManagedStatus(ctx, r.Client, current, dc.Spec.IsManagedCluster); err != nil {
+			return err
+		}
+
+		if dc.Spec.IsManagedCluster && !current.Spec.IsManagedCluster {
+			r.Recorder.Event(&current, corev1.EventTypeNormal, EventReasonManaged,
+				"Cluster is managed as a ManagedCluster")
+		}
+	}
+	return nil
 }
 
 func (r *DiscoveryConfigReconciler) createCluster(ctx context.Context, config *discovery.DiscoveryConfig, dc discovery.DiscoveredCluster) error {
@@ -445,35 +420,30 @@ func (r *DiscoveryConfigReconciler) createCluster(ctx context.Context, config *d
 		return nil
 	}
 
-	// Set controller reference
-	if err := ctrl.SetControllerReference(config, &dc, r.Scheme); err != nil {
-		return errors.Wrapf(err, "Error setting controller reference on DiscoveredCluster %s", dc.Name)
-	}
+	// The managed status is owned by a separate field manager, so it is applied once the cluster exists.
+	managed := dc.Spec.IsManagedCluster
+	unsetManagedStatus(&dc)
 
-	// Create the cluster
-	if err := r.Create(ctx, &dc); err != nil {
-		if apierrors.IsAlreadyExists(err) {
-			return nil
-		}
-		return errors.Wrapf(err, "Error creating DiscoveredCluster %s", dc.Name)
+	// Create the cluster with the syncer owning its fields through an Apply entry, as later updates are applied.
+	if err := applySourceFields(ctx, r.Client, config, &dc); err != nil {
+		return err
 	}
 
 	logf.Info("Created cluster", "Name", dc.Name)
-	return nil
-}
+	r.Recorder.Eventf(&dc, corev1.EventTypeNormal, EventReasonDiscovered,
+		"Discovered cluster %s in OpenShift Cluster Manager", dc.Spec.DisplayName)
 
-func (r *DiscoveryConfigReconciler) updateCluster(ctx context.Context, new, old discovery.DiscoveredCluster) error {
-	updated := old
-	updated.Spec = new.Spec
-	if err := r.Update(ctx, &updated); err != nil {
-		return errors.Wrapf(err, "Error updating DiscoveredCluster %s", updated.Name)
+	if managed {
+		if err := applyManagedStatus(ctx, r.Client, dc, true); err != nil {
+			return err
+		}
+		r.Recorder.Event(&dc, corev1.EventTypeNormal, EventReasonManaged, "Cluster is managed as a ManagedCluster")
 	}
-
-	logf.Info("Updated cluster", "Name", updated.Name)
 	return nil
 }
 
-func (r *DiscoveryConfigReconciler) deleteCluster(ctx context.Context, dc discovery.DiscoveredCluster) error {
+func (r *DiscoveryConfigReconciler) deleteCluster(ctx context.Context, config *discovery.DiscoveryConfig,
+	dc discovery.DiscoveredCluster) error {
 	if err := r.Delete(ctx, &dc); err != nil {
 		if apierrors.IsNotFound(err) {
 			logf.Info("Cluster does not exist, skipping deletion", "Name", dc.Name)
@@ -483,6 +453,8 @@ func (r *DiscoveryConfigReconciler) deleteCluster(ctx context.Context, dc discov
 	}
 
 	logf.Info("Deleted cluster", "Name", dc.Name)
+	r.Recorder.Eventf(config, corev1.EventTypeNormal, EventReasonRemoved,
+		"Cluster %s is no longer discovered and DiscoveredCluster %s was removed", dc.Spec.DisplayName, dc.Name)
 	return nil
 }
 
@@ -492,6 +464,8 @@ func (r *DiscoveryConfigReconciler) deleteAllClusters(ctx context.Context, confi
 		return errors.Wrapf(err, "Error clearing namespace %s", config.Namespace)
 	}
 	log.Info("Deleted all clusters", "Namespace", config.Namespace)
+	r.Recorder.Eventf(config, corev1.EventTypeNormal, EventReasonRemoved,
+		"Removed all DiscoveredClusters in namespace %s", config.Namespace)
 	return nil
 }
 
@@ -524,3 +498,19 @@ func getAuthURLOverride(config *discovery.DiscoveryConfig) string {
 	}
 	return ""
 }
+
+// getTrialExpiryWindow returns the trial expiry window set in the DiscoveryConfig, or the default window.
+func getTrialExpiryWindow(config *discovery.DiscoveryConfig) time.Duration {
+	if config != nil && config.Spec.TrialExpiryWindow != nil {
+		return config.Spec.TrialExpiryWindow.Duration
+	}
+	return discovery.DefaultTrialExpiryWindow
+}
+
+// getStaleAfter returns the staleness threshold set in the DiscoveryConfig, or nil if staleness is reported by OCM.
+func getStaleAfter(config *discovery.DiscoveryConfig) *time.Duration {
+	if config != nil && config.Spec.StaleAfter != nil && config.Spec.StaleAfter.Duration > 0 {
+		return &config.Spec.StaleAfter.Duration
+	}
+	return nil
+}
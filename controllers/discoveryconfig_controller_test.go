@@ -299,113 +299,6 @@ var _ = Describe("Discoveryconfig controller", func() {
 
 })
 
-func Test_parseSecretForAuth(t *testing.T) {
-	tests := []struct {
-		name    string
-		secret  *corev1.Secret
-		want    auth.AuthRequest
-		wantErr bool
-	}{
-		{
-			name: "Dummy token set",
-			secret: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test",
-					Namespace: "test",
-				},
-				Data: map[string][]byte{
-					"auth_method": []byte("offline-token"),
-					"ocmAPIToken": []byte("dummytoken"),
-				},
-			},
-			want: auth.AuthRequest{
-				AuthMethod: "offline-token",
-				Token:      "dummytoken",
-			},
-			wantErr: false,
-		},
-		{
-			name: "Missing token",
-			secret: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test",
-					Namespace: "test",
-				},
-			},
-			want: auth.AuthRequest{
-				AuthMethod: "offline-token",
-				Token:      "",
-			},
-			wantErr: true,
-		},
-		{
-			name: "Dummy service account token",
-			secret: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test",
-					Namespace: "test",
-				},
-				Data: map[string][]byte{
-					"auth_method":   []byte("service-account"),
-					"client_id":     []byte("dc05925d-630b-408b-bfb7-02099be7b789"),
-					"client_secret": []byte("ZZocNUZWgYSuJHIqK0j0D1mZVdufng6z"), // notsecret
-				},
-			},
-			want: auth.AuthRequest{
-				AuthMethod: "service-account",
-				ID:         "dc05925d-630b-408b-bfb7-02099be7b789",
-				Secret:     "ZZocNUZWgYSuJHIqK0j0D1mZVdufng6z", // notsecret
-			},
-			wantErr: false,
-		},
-		{
-			name: "Missing field service account",
-			secret: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test",
-					Namespace: "test",
-				},
-				Data: map[string][]byte{
-					"auth_method": []byte("service-account"),
-					"client_id":   []byte("dc05925d-630b-408b-bfb7-02099be7b789"),
-				},
-			},
-			want: auth.AuthRequest{
-				AuthMethod: "service-account",
-			},
-			wantErr: true,
-		},
-		{
-			name: "Invalid authentication method",
-			secret: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test",
-					Namespace: "test",
-				},
-				Data: map[string][]byte{
-					"auth_method": []byte("invalid-method"),
-				},
-			},
-			want: auth.AuthRequest{
-				AuthMethod: "invalid-method",
-			},
-			wantErr: true,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseSecretForAuth(tt.secret)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("parseSecretForAuth() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("parseSecretForAuth() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_assignManagedStatus(t *testing.T) {
 	discovered := map[string]discovery.DiscoveredCluster{
 		"a": {
@@ -520,6 +413,41 @@ func Test_getURLOverride(t *testing.T) {
 	}
 }
 
+func Test_getTrialExpiryWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *discovery.DiscoveryConfig
+		want   time.Duration
+	}{
+		{
+			name: "Window configured",
+			config: &discovery.DiscoveryConfig{
+				Spec: discovery.DiscoveryConfigSpec{
+					TrialExpiryWindow: &metav1.Duration{Duration: 48 * time.Hour},
+				},
+			},
+			want: 48 * time.Hour,
+		},
+		{
+			name:   "No window configured",
+			config: &discovery.DiscoveryConfig{},
+			want:   discovery.DefaultTrialExpiryWindow,
+		},
+		{
+			name:   "No DiscoveryConfig",
+			config: nil,
+			want:   discovery.DefaultTrialExpiryWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getTrialExpiryWindow(tt.config); got != tt.want {
+				t.Errorf("getTrialExpiryWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_getAuthURLOverride(t *testing.T) {
 	//config *discovery.DiscoveryConfig
 	tests := []struct {
@@ -0,0 +1,52 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+// Reasons of the events recorded on DiscoveredClusters and DiscoveryConfigs during their lifecycle.
+const (
+	// EventReasonDiscovered is recorded on a DiscoveredCluster when it is first discovered in OCM.
+	EventReasonDiscovered = "Discovered"
+
+	// EventReasonRemoved is recorded on a DiscoveryConfig when a cluster is no longer discovered in OCM.
+	EventReasonRemoved = "Removed"
+
+	// EventReasonStale is recorded on a DiscoveredCluster when it transitions from Active to Stale.
+	EventReasonStale = "Stale"
+
+	/*
+		EventReasonUnavailable is recorded on a DiscoveredCluster when it stops being available for another reason than
+		stale telemetry, such as being disconnected, deprovisioned or archived in OCM.
+	*/
+	EventReasonUnavailable = "Unavailable"
+
+	// EventReasonVersionChanged is recorded on a DiscoveredCluster when its OpenShift version changes.
+	EventReasonVersionChanged = "VersionChanged"
+
+	// EventReasonManaged is recorded on a DiscoveredCluster when it becomes a ManagedCluster.
+	EventReasonManaged = "Managed"
+
+	// EventReasonImportStarted is recorded on a DiscoveredCluster when its automatic import begins.
+	EventReasonImportStarted = "ImportStarted"
+
+	// EventReasonImportFailed is recorded on a DiscoveredCluster when its automatic import fails.
+	EventReasonImportFailed = "ImportFailed"
+
+	// EventReasonImportPolicyApplied is recorded on a DiscoveredCluster when a DiscoveryImportPolicy triggers its import.
+	EventReasonImportPolicyApplied = "ImportPolicyApplied"
+
+	// EventReasonReimportPending is recorded on a DiscoveredCluster when its reimport waits for the previous
+	// ManagedCluster to be deleted.
+	EventReasonReimportPending = "ReimportPending"
+
+	// EventReasonReimported is recorded on a DiscoveredCluster when its import is restarted by a reimport request.
+	EventReasonReimported = "Reimported"
+
+	/*
+		EventReasonAutoImportSecretUnmanaged is recorded on a DiscoveredCluster when its import credential changed but
+		the auto-import secret was not created by discovery, so it is not updated.
+	*/
+	EventReasonAutoImportSecretUnmanaged = "AutoImportSecretUnmanaged"
+
+	// EventReasonCredentialInvalid is recorded on a DiscoveryConfig when its OCM credential cannot be used.
+	EventReasonCredentialInvalid = "CredentialInvalid"
+)
@@ -0,0 +1,173 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	"github.com/stolostron/discovery/pkg/platform"
+	recon "github.com/stolostron/discovery/util/reconciler"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+/*
+getHostedAddOnSettings returns the settings of the addons installed on the imported MultiClusterEngineHCP clusters, with
+defaults for the settings that are not set. The addons are configured for the whole hub, so the settings are read from
+the first DiscoveryConfig, by namespace, that sets them. The DiscoveryConfig webhook rejects conflicting settings.
+*/
+func (r *DiscoveredClusterReconciler) getHostedAddOnSettings(ctx context.Context) (discovery.HostedAddOnSettings,
+	error) {
+	configs := &discovery.DiscoveryConfigList{}
+	if err := r.List(ctx, configs); err != nil {
+		return discovery.HostedAddOnSettings{}, errors.Wrap(err, "failed to list DiscoveryConfigs")
+	}
+	slices.SortFunc(configs.Items, func(a, b discovery.DiscoveryConfig) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+
+	settings := discovery.HostedAddOnSettings{}
+	for _, config := range configs.Items {
+		if config.Spec.HostedAddOns != nil {
+			settings = *config.Spec.HostedAddOns.DeepCopy()
+			break
+		}
+	}
+
+	if len(settings.AddOns) == 0 {
+		settings.AddOns = slices.Clone(discovery.DefaultHostedAddOns)
+	}
+	if settings.PlacementName == "" {
+		settings.PlacementName = DefaultName
+	}
+	if settings.PlacementNamespace == "" {
+		settings.PlacementNamespace = os.Getenv("POD_NAMESPACE")
+	}
+	if settings.AgentInstallNamespace == "" {
+		settings.AgentInstallNamespace = discovery.DefaultHostedAddOnInstallNamespace
+	}
+	return settings, nil
+}
+
+// applyHostedAddOnSettings sets the agent install namespace and node placement of the settings on the
+// AddOnDeploymentConfig.
+func applyHostedAddOnSettings(adc *addonv1alpha1.AddOnDeploymentConfig, settings discovery.HostedAddOnSettings) {
+	adc.Spec.AgentInstallNamespace = settings.AgentInstallNamespace
+
+	adc.Spec.NodePlacement = nil
+	if len(settings.NodeSelector) > 0 || len(settings.Tolerations) > 0 {
+		adc.Spec.NodePlacement = &addonv1alpha1.NodePlacement{
+			NodeSelector: settings.NodeSelector,
+			Tolerations:  settings.Tolerations,
+		}
+	}
+}
+
+// isHostedAddOnPlacementStrategy returns true if the placement strategy was added for the hosted addons, which is the
+// case when it references the AddOnDeploymentConfig of the hosted addons.
+func isHostedAddOnPlacementStrategy(p addonv1alpha1.PlacementStrategy) bool {
+	for _, config := range p.Configs {
+		if config.Resource == "addondeploymentconfigs" && config.Name == AddOnDeploymentConfigName &&
+			config.Namespace == os.Getenv("POD_NAMESPACE") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ensureHostedAddOns ensures the resources that install the hosted addons on MultiClusterEngineHCP clusters match the
+hosted addon settings. Existing resources are updated when the settings change, and the Placement of the hosted addons
+is removed from the ClusterManagementAddOns that are no longer configured. The ClusterManagementAddOns are only listed
+for that when the settings differ from the last ones applied by the reconciler.
+*/
+func (r *DiscoveredClusterReconciler) ensureHostedAddOns(ctx context.Context, dc discovery.DiscoveredCluster) (
+	ctrl.Result, error) {
+	if !r.Capabilities.Has(platform.ClusterManagementAddOn) {
+		logf.V(1).Info("ClusterManagementAddOn API is not served by the hub. Skipping hosted addons.",
+			"Name", dc.Spec.DisplayName)
+		return ctrl.Result{}, nil
+	}
+
+	settings, err := r.getHostedAddOnSettings(ctx)
+	if err != nil {
+		logf.Error(err, "failed to get hosted addon settings", "Name", dc.Spec.DisplayName)
+		return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+	}
+
+	// The Placement of the hosted addons only selects clusters in ManagedClusterSets bound to its namespace.
+	clusterSet := getClusterSet(r.getManagedClusterSettings(ctx, dc))
+	if res, err := r.EnsureManagedClusterSetBinding(ctx, clusterSet, settings.PlacementNamespace); err != nil {
+		logf.Error(err, "failed to ensure ManagedClusterBindingSet created", "Name", clusterSet,
+			"Namespace", settings.PlacementNamespace)
+		return res, err
+	}
+
+	placement := types.NamespacedName{Name: settings.PlacementName, Namespace: settings.PlacementNamespace}
+	if res, err := r.EnsurePlacement(ctx, placement); err != nil {
+		logf.Error(err, "failed to ensure Placement created", "Name", placement.Name, "Namespace", placement.Namespace)
+		return res, err
+	}
+
+	if res, err := r.EnsureAddOnDeploymentConfig(ctx, settings); err != nil {
+		logf.Error(err, "failed to ensure AddOnDeploymentConfig created", "Name", AddOnDeploymentConfigName,
+			"Namespace", os.Getenv("POD_NAMESPACE"))
+		return res, err
+	}
+
+	for _, addon := range settings.AddOns {
+		if res, err := r.AddPlacementToClusterManagementAddOn(ctx, addon, settings); err != nil {
+			return res, err
+		}
+	}
+
+	r.hostedAddOnsMu.Lock()
+	defer r.hostedAddOnsMu.Unlock()
+	if r.appliedHostedAddOns != nil && equality.Semantic.DeepEqual(*r.appliedHostedAddOns, settings) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.removeHostedAddOnPlacements(ctx, settings); err != nil {
+		logf.Error(err, "failed to remove Placement from ClusterManagementAddOns", "Name", placement.Name)
+		return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
+	}
+	r.appliedHostedAddOns = &settings
+	return ctrl.Result{}, nil
+}
+
+// removeHostedAddOnPlacements removes the Placement of the hosted addons from the ClusterManagementAddOns that are not
+// in the settings.
+func (r *DiscoveredClusterReconciler) removeHostedAddOnPlacements(ctx context.Context,
+	settings discovery.HostedAddOnSettings) error {
+	cmas := &addonv1alpha1.ClusterManagementAddOnList{}
+	if err := r.List(ctx, cmas); err != nil {
+		return errors.Wrap(err, "failed to list ClusterManagementAddOns")
+	}
+
+	for i := range cmas.Items {
+		cma := &cmas.Items[i]
+		if slices.Contains(settings.AddOns, cma.Name) {
+			continue
+		}
+
+		placements := slices.DeleteFunc(slices.Clone(cma.Spec.InstallStrategy.Placements),
+			isHostedAddOnPlacementStrategy)
+		if equality.Semantic.DeepEqual(placements, cma.Spec.InstallStrategy.Placements) {
+			continue
+		}
+
+		logf.Info("Removing hosted addon Placement from ClusterManagementAddOn", "Name", cma.Name)
+		cma.Spec.InstallStrategy.Placements = placements
+		if err := r.Update(ctx, cma); err != nil {
+			return errors.Wrapf(err, "failed to update ClusterManagementAddOn %s", cma.Name)
+		}
+	}
+	return nil
+}
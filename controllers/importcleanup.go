@@ -0,0 +1,125 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	utils "github.com/stolostron/discovery/util"
+	agentv1 "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// setCreatedBy labels a resource created to import the DiscoveredCluster, so that it can be cleaned up later.
+func setCreatedBy(obj client.Object, dc discovery.DiscoveredCluster) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[utils.LabelCreatedBy] = string(dc.UID)
+	obj.SetLabels(labels)
+}
+
+// isCreatedBy reports whether the resource was created to import the DiscoveredCluster.
+func isCreatedBy(obj client.Object, dc discovery.DiscoveredCluster) bool {
+	uid, found := obj.GetLabels()[utils.LabelCreatedBy]
+	return found && uid != "" && uid == string(dc.UID)
+}
+
+// addImportCleanUpFinalizer adds the import cleanup finalizer to the DiscoveredCluster if it is missing.
+func (r *DiscoveredClusterReconciler) addImportCleanUpFinalizer(ctx context.Context,
+	dc *discovery.DiscoveredCluster) error {
+	if controllerutil.ContainsFinalizer(dc, discovery.ImportCleanUpFinalizer) {
+		return nil
+	}
+
+	patch := client.MergeFrom(dc.DeepCopy())
+	controllerutil.AddFinalizer(dc, discovery.ImportCleanUpFinalizer)
+	if err := r.Patch(ctx, dc, patch); err != nil {
+		return errors.Wrapf(err, "failed to add finalizer to DiscoveredCluster %s", dc.Name)
+	}
+	return nil
+}
+
+/*
+finalizeImport removes the import artifacts created for the DiscoveredCluster and then removes the import cleanup
+finalizer. It is called when the DiscoveredCluster is deleted, or when its import is abandoned.
+*/
+func (r *DiscoveredClusterReconciler) finalizeImport(ctx context.Context, dc *discovery.DiscoveredCluster) error {
+	if !controllerutil.ContainsFinalizer(dc, discovery.ImportCleanUpFinalizer) {
+		return nil
+	}
+
+	if err := r.cleanupImportArtifacts(ctx, *dc); err != nil {
+		return err
+	}
+	return r.removeImportCleanUpFinalizer(ctx, dc)
+}
+
+// removeImportCleanUpFinalizer removes the import cleanup finalizer from the DiscoveredCluster if it is present.
+func (r *DiscoveredClusterReconciler) removeImportCleanUpFinalizer(ctx context.Context,
+	dc *discovery.DiscoveredCluster) error {
+	if !controllerutil.ContainsFinalizer(dc, discovery.ImportCleanUpFinalizer) {
+		return nil
+	}
+
+	patch := client.MergeFrom(dc.DeepCopy())
+	controllerutil.RemoveFinalizer(dc, discovery.ImportCleanUpFinalizer)
+	if err := r.Patch(ctx, dc, patch); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to remove finalizer from DiscoveredCluster %s", dc.Name)
+	}
+	return nil
+}
+
+/*
+cleanupImportArtifacts deletes the Namespace, auto-import Secret, KlusterletAddonConfig and ManagedCluster created to
+import the DiscoveredCluster. Resources that are not labeled as created for this DiscoveredCluster already existed and
+are never deleted. Once the ManagedCluster has joined the hub, the artifacts belong to the ManagedCluster and are kept.
+*/
+func (r *DiscoveredClusterReconciler) cleanupImportArtifacts(ctx context.Context,
+	dc discovery.DiscoveredCluster) error {
+	if mc := r.getManagedCluster(ctx, &dc); mc != nil &&
+		meta.IsStatusConditionTrue(mc.Status.Conditions, clusterapiv1.ManagedClusterConditionJoined) {
+		logf.Info("ManagedCluster has joined the hub. Keeping import artifacts.", "Name", mc.Name)
+		return nil
+	}
+
+	name := discovery.GetManagedClusterName(&dc)
+	artifacts := []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "auto-import-secret", Namespace: name}},
+		&agentv1.KlusterletAddonConfig{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: name}},
+		&clusterapiv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}},
+	}
+
+	for _, obj := range artifacts {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			// The KlusterletAddonConfig CRD is not deployed in standalone MCE mode.
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get %T %s", obj, obj.GetName())
+		}
+
+		if !isCreatedBy(obj, dc) {
+			continue
+		}
+
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %T %s", obj, obj.GetName())
+		}
+		logf.Info("Deleted import artifact", "Type", fmt.Sprintf("%T", obj), "Name", obj.GetName(),
+			"Namespace", obj.GetNamespace())
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	utils "github.com/stolostron/discovery/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+/*
+isImportPolicyCandidate reports whether a DiscoveryImportPolicy may set the import intent of the DiscoveredCluster.
+Clusters that are already importing, managed, previously imported, or that were already selected by a policy are left
+to the user, so that a user who turns off importAsManagedCluster is not overridden.
+*/
+func isImportPolicyCandidate(dc *discovery.DiscoveredCluster, strategy string) bool {
+	if dc.Spec.ImportAsManagedCluster || dc.Spec.IsManagedCluster || strategy == discovery.ImportStrategyDisabled {
+		return false
+	}
+
+	if _, found := dc.GetAnnotations()[discovery.ImportPolicyAnnotation]; found {
+		return false
+	}
+
+	return !utils.IsAnnotationTrue(dc, utils.AnnotationPreviouslyAutoImported) &&
+		discovery.IsSupportedClusterType(dc.Spec.Type) &&
+		discovery.GetPreferredManagedClusterName(dc) != ""
+}
+
+/*
+applyImportPolicies evaluates the DiscoveryImportPolicies in the namespace of the DiscoveredCluster in name order and
+sets the import intent of the cluster for the first policy that selects it. The policy is recorded in annotations on
+the DiscoveredCluster. When the policy has reached its rate limit, the cluster is left unchanged and the time after
+which the policy can trigger another import is returned.
+*/
+func (r *DiscoveredClusterReconciler) applyImportPolicies(ctx context.Context, dc *discovery.DiscoveredCluster,
+	strategy string, now time.Time) (time.Duration, error) {
+	if !isImportPolicyCandidate(dc, strategy) {
+		return 0, nil
+	}
+
+	policies := &discovery.DiscoveryImportPolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(dc.Namespace)); err != nil {
+		return 0, errors.Wrapf(err, "failed to list DiscoveryImportPolicies in namespace %s", dc.Namespace)
+	}
+
+	sort.Slice(policies.Items, func(i, j int) bool { return policies.Items[i].Name < policies.Items[j].Name })
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+
+		matched, err := policy.Matches(dc)
+		if err != nil {
+			logf.Error(err, "Skipping invalid DiscoveryImportPolicy", "Name", policy.Name, "Namespace", policy.Namespace)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		wait, err := r.importPolicyRateLimitWait(ctx, policy, dc, now)
+		if err != nil {
+			return 0, err
+		}
+		if wait > 0 {
+			logf.Info("DiscoveryImportPolicy reached its rate limit. Delaying import.", "Name", dc.Spec.DisplayName,
+				"Policy", policy.Name, "RetryAfter", wait)
+			return wait, nil
+		}
+
+		patch := client.MergeFrom(dc.DeepCopy())
+		if dc.Annotations == nil {
+			dc.Annotations = map[string]string{}
+		}
+		dc.Annotations[discovery.ImportPolicyAnnotation] = policy.Name
+		dc.Annotations[discovery.ImportPolicyTimestampAnnotation] = now.UTC().Format(time.RFC3339)
+		dc.Spec.ImportAsManagedCluster = true
+
+		if err := r.Patch(ctx, dc, patch); err != nil {
+			r.policyImports.release(policy, dc)
+			return 0, errors.Wrapf(err, "failed to set import intent of DiscoveredCluster %s", dc.Name)
+		}
+
+		logf.Info("Import triggered by DiscoveryImportPolicy", "Name", dc.Spec.DisplayName, "Policy", policy.Name)
+		r.Recorder.Eventf(dc, corev1.EventTypeNormal, EventReasonImportPolicyApplied,
+			"Import triggered by DiscoveryImportPolicy %s", policy.Name)
+		return 0, nil
+	}
+
+	return 0, nil
+}
+
+/*
+importPolicyImports records the imports triggered by each DiscoveryImportPolicy in memory. Reconciles of different
+clusters run in parallel and the cache may not yet contain the annotations of the clusters patched by the others, so
+the recorded imports are counted in addition to the annotated ones to keep the policy within its rate limit.
+*/
+type importPolicyImports struct {
+	mu      sync.Mutex
+	imports map[types.NamespacedName]map[types.NamespacedName]time.Time
+}
+
+// release forgets the import of the DiscoveredCluster by the policy, such as when its import intent could not be set.
+func (i *importPolicyImports) release(policy *discovery.DiscoveryImportPolicy, dc *discovery.DiscoveredCluster) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.imports[client.ObjectKeyFromObject(policy)], client.ObjectKeyFromObject(dc))
+}
+
+/*
+importPolicyRateLimitWait returns how long to wait before the policy can trigger another import, or zero if the policy
+is below its rate limit. Imports are counted from the annotations of the DiscoveredClusters in the namespace of the
+policy and from the imports recorded in memory. When the policy is below its rate limit, the import of the
+DiscoveredCluster is recorded before returning, so that parallel reconciles do not exceed the limit.
+*/
+func (r *DiscoveredClusterReconciler) importPolicyRateLimitWait(ctx context.Context,
+	policy *discovery.DiscoveryImportPolicy, dc *discovery.DiscoveredCluster, now time.Time) (time.Duration, error) {
+	if policy.Spec.RateLimit == nil {
+		return 0, nil
+	}
+
+	r.policyImports.mu.Lock()
+	defer r.policyImports.mu.Unlock()
+
+	discoveredClusters := &discovery.DiscoveredClusterList{}
+	if err := r.List(ctx, discoveredClusters, client.InNamespace(policy.Namespace)); err != nil {
+		return 0, errors.Wrapf(err, "failed to list DiscoveredClusters in namespace %s", policy.Namespace)
+	}
+
+	period := policy.GetRateLimitPeriod()
+	windowStart := now.Add(-period)
+
+	policyKey := client.ObjectKeyFromObject(policy)
+	if r.policyImports.imports == nil {
+		r.policyImports.imports = map[types.NamespacedName]map[types.NamespacedName]time.Time{}
+	}
+	recorded, found := r.policyImports.imports[policyKey]
+	if !found {
+		recorded = map[types.NamespacedName]time.Time{}
+		r.policyImports.imports[policyKey] = recorded
+	}
+
+	importsByCluster := map[types.NamespacedName]time.Time{}
+	for key, triggered := range recorded {
+		if !triggered.After(windowStart) {
+			delete(recorded, key)
+			continue
+		}
+		importsByCluster[key] = triggered
+	}
+	for _, item := range discoveredClusters.Items {
+		if item.GetAnnotations()[discovery.ImportPolicyAnnotation] != policy.Name {
+			continue
+		}
+
+		triggered, err := time.Parse(time.RFC3339, item.GetAnnotations()[discovery.ImportPolicyTimestampAnnotation])
+		if err != nil || !triggered.After(windowStart) {
+			continue
+		}
+		importsByCluster[client.ObjectKeyFromObject(&item)] = triggered
+	}
+
+	if len(importsByCluster) < int(policy.Spec.RateLimit.MaxImports) {
+		recorded[client.ObjectKeyFromObject(dc)] = now
+		return 0, nil
+	}
+
+	// Wait until enough imports have left the window to trigger one more.
+	imports := make([]time.Time, 0, len(importsByCluster))
+	for _, triggered := range importsByCluster {
+		imports = append(imports, triggered)
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Before(imports[j]) })
+	oldest := imports[len(imports)-int(policy.Spec.RateLimit.MaxImports)]
+	return oldest.Add(period).Sub(now), nil
+}
+
+// getImportPolicy returns the DiscoveryImportPolicy that triggered the import of the DiscoveredCluster, if any.
+func (r *DiscoveredClusterReconciler) getImportPolicy(ctx context.Context,
+	dc discovery.DiscoveredCluster) *discovery.DiscoveryImportPolicy {
+	name, found := dc.GetAnnotations()[discovery.ImportPolicyAnnotation]
+	if !found {
+		return nil
+	}
+
+	policy := &discovery.DiscoveryImportPolicy{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: dc.Namespace}, policy); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logf.Error(err, "failed to get DiscoveryImportPolicy", "Name", name, "Namespace", dc.Namespace)
+		}
+		return nil
+	}
+	return policy
+}
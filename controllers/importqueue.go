@@ -0,0 +1,273 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	discovery "github.com/stolostron/discovery/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Orderings of the import queue.
+const (
+	// ImportQueueOrderingFIFO starts imports in the order clusters were queued.
+	ImportQueueOrderingFIFO = "FIFO"
+
+	// ImportQueueOrderingPriority starts imports by decreasing import priority, then in the order clusters were queued.
+	ImportQueueOrderingPriority = "Priority"
+)
+
+const (
+	// importQueueRetryInterval is how often a queued cluster checks whether its import can start.
+	importQueueRetryInterval = 15 * time.Second
+
+	// importQueueSlotTimeout is how long an import holds a concurrency slot when it neither completes nor fails.
+	importQueueSlotTimeout = 30 * time.Minute
+)
+
+type importQueueEntry struct {
+	priority int
+	sequence uint64
+}
+
+/*
+ImportQueue limits how many imports of DiscoveredClusters run at the same time and how many start per minute, so that
+enabling the import of many clusters at once does not overwhelm the hub. Clusters wait in the queue until they reach a
+free slot in FIFO or priority order. A zero limit disables the corresponding check. The queue is kept in memory, and
+imports that started before a restart are counted again as they are reconciled.
+*/
+type ImportQueue struct {
+	MaxConcurrentImports int
+	ImportsPerMinute     int
+	Ordering             string
+
+	mu         sync.Mutex
+	waiting    map[types.NamespacedName]*importQueueEntry
+	active     map[types.NamespacedName]time.Time
+	tokens     float64
+	lastRefill time.Time
+	sequence   uint64
+}
+
+// NewImportQueue returns an ImportQueue with the given limits and ordering.
+func NewImportQueue(maxConcurrentImports, importsPerMinute int, ordering string) *ImportQueue {
+	return &ImportQueue{
+		MaxConcurrentImports: maxConcurrentImports,
+		ImportsPerMinute:     importsPerMinute,
+		Ordering:             ordering,
+		waiting:              map[types.NamespacedName]*importQueueEntry{},
+		active:               map[types.NamespacedName]time.Time{},
+		tokens:               float64(importsPerMinute),
+	}
+}
+
+// getImportPriority returns the import priority of the DiscoveredCluster, or 0 if it is not set or invalid.
+func getImportPriority(dc *discovery.DiscoveredCluster) int {
+	priority, err := strconv.Atoi(dc.GetAnnotations()[discovery.ImportPriorityAnnotation])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+/*
+Admit queues the import of the DiscoveredCluster and returns true once it can start. Clusters whose import already
+started are always admitted. While the cluster waits, its position in the queue and the time after which it should
+try again are returned.
+*/
+func (q *ImportQueue) Admit(dc *discovery.DiscoveredCluster, now time.Time) (bool, int, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace}
+	if _, found := q.active[key]; found {
+		return true, 0, 0
+	}
+
+	entry, found := q.waiting[key]
+	if !found {
+		q.sequence++
+		entry = &importQueueEntry{sequence: q.sequence}
+		q.waiting[key] = entry
+	}
+	entry.priority = getImportPriority(dc)
+
+	q.refill(now)
+	position := q.position(key)
+
+	if q.MaxConcurrentImports > 0 && position > q.MaxConcurrentImports-q.activeImports(now) {
+		return false, position, importQueueRetryInterval
+	}
+
+	if q.ImportsPerMinute > 0 && float64(position) > q.tokens {
+		wait := time.Duration((float64(position) - q.tokens) / float64(q.ImportsPerMinute) * float64(time.Minute))
+		return false, position, max(wait, time.Second)
+	}
+
+	delete(q.waiting, key)
+	q.active[key] = now
+	if q.ImportsPerMinute > 0 {
+		q.tokens--
+	}
+	return true, 0, 0
+}
+
+// MarkStarted counts the import of the DiscoveredCluster as running, for imports that started before a restart.
+func (q *ImportQueue) MarkStarted(key types.NamespacedName, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.waiting, key)
+	if _, found := q.active[key]; !found {
+		q.active[key] = now
+	}
+}
+
+// Done removes the DiscoveredCluster from the queue and frees its slot once its import completed, failed or was
+// abandoned.
+func (q *ImportQueue) Done(key types.NamespacedName) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.waiting, key)
+	delete(q.active, key)
+}
+
+// Position returns the position of the DiscoveredCluster in the queue and the length of the queue. The position is 0
+// if the cluster is not waiting.
+func (q *ImportQueue) Position(key types.NamespacedName) (int, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, found := q.waiting[key]; !found {
+		return 0, len(q.waiting)
+	}
+	return q.position(key), len(q.waiting)
+}
+
+// position returns the 1-based position of the waiting cluster. The caller must hold the lock.
+func (q *ImportQueue) position(key types.NamespacedName) int {
+	keys := make([]types.NamespacedName, 0, len(q.waiting))
+	for k := range q.waiting {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := q.waiting[keys[i]], q.waiting[keys[j]]
+		if q.Ordering == ImportQueueOrderingPriority && a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.sequence < b.sequence
+	})
+
+	for i, k := range keys {
+		if k == key {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// activeImports returns the number of running imports, dropping those that exceeded the slot timeout. The caller must
+// hold the lock.
+func (q *ImportQueue) activeImports(now time.Time) int {
+	for k, startedAt := range q.active {
+		if now.Sub(startedAt) > importQueueSlotTimeout {
+			delete(q.active, k)
+		}
+	}
+	return len(q.active)
+}
+
+// refill adds the tokens earned since the last refill, up to one minute of imports. The caller must hold the lock.
+func (q *ImportQueue) refill(now time.Time) {
+	if q.ImportsPerMinute <= 0 {
+		return
+	}
+
+	if !q.lastRefill.IsZero() {
+		q.tokens += now.Sub(q.lastRefill).Minutes() * float64(q.ImportsPerMinute)
+	}
+	q.tokens = min(q.tokens, float64(q.ImportsPerMinute))
+	q.lastRefill = now
+}
+
+// isImportFinished returns true if the import of the ManagedCluster completed or failed.
+func isImportFinished(dc *discovery.DiscoveredCluster, mc *clusterapiv1.ManagedCluster, now time.Time) bool {
+	condition := buildImportedCondition(dc, mc, false, now)
+	return condition.Status == metav1.ConditionTrue || condition.Reason == discovery.ReasonImportFailed
+}
+
+/*
+admitImport returns true if the import of the DiscoveredCluster can proceed. Imports are always admitted when no import
+queue is configured, for cluster types that are not imported, while they wait for approval since only their
+prerequisites are created, and once their ManagedCluster exists. Otherwise the cluster is queued, and the time after
+which it should try again is returned.
+*/
+func (r *DiscoveredClusterReconciler) admitImport(ctx context.Context, dc *discovery.DiscoveredCluster, strategy string,
+	now time.Time) (bool, time.Duration) {
+	if r.ImportQueue == nil || !discovery.IsSupportedClusterType(dc.Spec.Type) || isAwaitingApproval(dc, strategy) {
+		return true, 0
+	}
+
+	key := client.ObjectKeyFromObject(dc)
+	if mc := r.getManagedCluster(ctx, dc); mc != nil {
+		if isImportFinished(dc, mc, now) {
+			r.ImportQueue.Done(key)
+		} else {
+			r.ImportQueue.MarkStarted(key, now)
+		}
+		return true, 0
+	}
+
+	admitted, position, wait := r.ImportQueue.Admit(dc, now)
+	if !admitted {
+		logf.Info("Import queued", "Name", dc.Spec.DisplayName, "Position", position, "RetryAfter", wait)
+	}
+	return admitted, wait
+}
+
+// releaseImport removes the DiscoveredCluster from the import queue when it is no longer imported.
+func (r *DiscoveredClusterReconciler) releaseImport(key types.NamespacedName) {
+	if r.ImportQueue != nil {
+		r.ImportQueue.Done(key)
+	}
+}
+
+// buildImportQueuedCondition constructs the ImportQueued condition of a DiscoveredCluster that is imported.
+func (r *DiscoveredClusterReconciler) buildImportQueuedCondition(dc *discovery.DiscoveredCluster,
+	now time.Time) discovery.DiscoveredClusterCondition {
+	condition := discovery.DiscoveredClusterCondition{
+		Type:               discovery.ConditionImportQueued,
+		Status:             metav1.ConditionFalse,
+		Reason:             discovery.ReasonImportDequeued,
+		Message:            "Import is not waiting in the import queue",
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	if position, length := r.ImportQueue.Position(client.ObjectKeyFromObject(dc)); position > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = discovery.ReasonImportQueued
+		condition.Message = fmt.Sprintf("Import is queued at position %d of %d", position, length)
+	}
+	return condition
+}
+
+// getImportQueuePosition returns the position of the DiscoveredCluster in the import queue, or 0 if it is not waiting.
+func (r *DiscoveredClusterReconciler) getImportQueuePosition(dc *discovery.DiscoveredCluster) int32 {
+	if r.ImportQueue == nil {
+		return 0
+	}
+	position, _ := r.ImportQueue.Position(client.ObjectKeyFromObject(dc))
+	return int32(position)
+}
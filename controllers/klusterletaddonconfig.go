@@ -0,0 +1,130 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	"github.com/stolostron/discovery/pkg/platform"
+	utils "github.com/stolostron/discovery/util"
+	agentv1 "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+/*
+getKlusterletAddonConfigTemplate returns the KlusterletAddonConfig template of the DiscoveredCluster. The template of
+the DiscoveryConfig is overridden by the template of the DiscoveryImportPolicy that triggered the import.
+*/
+func (r *DiscoveredClusterReconciler) getKlusterletAddonConfigTemplate(ctx context.Context,
+	dc discovery.DiscoveredCluster) discovery.KlusterletAddonConfigTemplate {
+	template := discovery.KlusterletAddonConfigTemplate{}
+
+	if config := r.getDiscoveryConfig(ctx, dc.Namespace); config != nil {
+		mergeKlusterletAddonConfigTemplate(&template, config.Spec.KlusterletAddonConfigTemplate)
+	}
+
+	if policy := r.getImportPolicy(ctx, dc); policy != nil {
+		mergeKlusterletAddonConfigTemplate(&template, policy.Spec.KlusterletAddonConfigTemplate)
+	}
+	return template
+}
+
+// mergeKlusterletAddonConfigTemplate merges the overrides into the template. The overrides may be nil.
+func mergeKlusterletAddonConfigTemplate(template, overrides *discovery.KlusterletAddonConfigTemplate) {
+	if overrides == nil {
+		return
+	}
+
+	for _, field := range []struct{ dst, src **bool }{
+		{&template.ApplicationManager, &overrides.ApplicationManager},
+		{&template.CertPolicyController, &overrides.CertPolicyController},
+		{&template.PolicyController, &overrides.PolicyController},
+		{&template.SearchCollector, &overrides.SearchCollector},
+	} {
+		if *field.src != nil {
+			*field.dst = *field.src
+		}
+	}
+
+	for k, v := range overrides.ClusterLabels {
+		if template.ClusterLabels == nil {
+			template.ClusterLabels = map[string]string{}
+		}
+		template.ClusterLabels[k] = v
+	}
+}
+
+/*
+applyKlusterletAddonConfigTemplate merges the template into the spec of the KlusterletAddonConfig. Addons that are not
+set in the template are enabled.
+*/
+func applyKlusterletAddonConfigTemplate(kac *agentv1.KlusterletAddonConfig,
+	template discovery.KlusterletAddonConfigTemplate) {
+	for _, addon := range []struct {
+		enabled *bool
+		config  *agentv1.KlusterletAddonAgentConfigSpec
+	}{
+		{template.ApplicationManager, &kac.Spec.ApplicationManagerConfig},
+		{template.CertPolicyController, &kac.Spec.CertPolicyControllerConfig},
+		{template.PolicyController, &kac.Spec.PolicyController},
+		{template.SearchCollector, &kac.Spec.SearchCollectorConfig},
+	} {
+		addon.config.Enabled = addon.enabled == nil || *addon.enabled
+	}
+
+	if kac.Spec.ClusterLabels == nil {
+		kac.Spec.ClusterLabels = map[string]string{}
+	}
+	for k, v := range template.ClusterLabels {
+		if k == utils.LabelName {
+			continue
+		}
+		kac.Spec.ClusterLabels[k] = v
+	}
+}
+
+/*
+syncKlusterletAddonConfig reconciles the KlusterletAddonConfig template onto the KlusterletAddonConfig created by the
+discovery operator to import the DiscoveredCluster, so that template changes also apply to clusters that are already
+imported. KlusterletAddonConfigs created by others are left unchanged.
+*/
+func (r *DiscoveredClusterReconciler) syncKlusterletAddonConfig(ctx context.Context,
+	dc discovery.DiscoveredCluster) error {
+	if !r.Capabilities.Has(platform.KlusterletAddonConfig) {
+		return nil
+	}
+
+	name := discovery.GetManagedClusterName(&dc)
+	nn := types.NamespacedName{Name: name, Namespace: name}
+	kac := &agentv1.KlusterletAddonConfig{}
+	if err := r.Get(ctx, nn, kac); err != nil {
+		// In standalone MCE mode, the KlusterletAddonConfig CRD is not deployed.
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get KlusterletAddonConfig %s", nn.Name)
+	}
+
+	if !isCreatedBy(kac, dc) {
+		return nil
+	}
+
+	desired := kac.DeepCopy()
+	desired.Spec.ClusterLabels = r.CreateKlusterletAddonConfig(nn).Spec.ClusterLabels
+	applyKlusterletAddonConfigTemplate(desired, r.getKlusterletAddonConfigTemplate(ctx, dc))
+
+	if equality.Semantic.DeepEqual(kac.Spec, desired.Spec) {
+		return nil
+	}
+
+	logf.Info("Updating KlusterletAddonConfig from template", "Name", nn.Name, "Namespace", nn.Namespace)
+	if err := r.Update(ctx, desired); err != nil {
+		return errors.Wrapf(err, "failed to update KlusterletAddonConfig %s", nn.Name)
+	}
+	return nil
+}
@@ -23,10 +23,12 @@ import (
 	discovery "github.com/stolostron/discovery/api/v1"
 	utils "github.com/stolostron/discovery/util"
 	recon "github.com/stolostron/discovery/util/reconciler"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -45,9 +47,10 @@ var managedClusterGVK = schema.GroupVersionKind{
 // ManagedClusterReconciler reconciles a ManagedCluster object
 type ManagedClusterReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	Trigger chan event.GenericEvent
-	Log     logr.Logger
+	Scheme   *runtime.Scheme
+	Trigger  chan event.GenericEvent
+	Log      logr.Logger
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=agent.open-cluster-management.io,resources=klusterletaddonconfigs;klusterletaddonconfigs/finalizers;klusterletaddonconfigs/status,verbs=create;delete;get;list;patch;update;watch
@@ -79,32 +82,40 @@ func (r *ManagedClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		logf.Info("ManagedCluster is being deleted", "Name", mc.GetName(), "DeletionTimestamp",
 			mc.GetDeletionTimestamp())
 
-		for i := range discoveredClusters.Items {
-			dc := &discoveredClusters.Items[i]
-
-			if dc.GetName() == req.Name || dc.Spec.DisplayName == req.Name {
-				modifiedDC := dc.DeepCopy()
-
-				/*
-					Set annotation to true on DiscoveredCluster resource to prevent automatic import.
-					The user will need to manually remove the annotation if they want the cluster to be reimported
-					automatically.
-				*/
-				if modifiedDC.Spec.ImportAsManagedCluster {
-					modifiedDC.SetAnnotations(map[string]string{
-						utils.AnnotationPreviouslyAutoImported: "true",
-					})
+		imported, err := r.getImportedDiscoveredClusters(ctx, mc, discoveredClusters)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: recon.WarningRefreshInterval}, err
+		}
 
-					logf.Info(fmt.Sprintf("Added '%v' annotation to DiscoveredCluster",
-						utils.AnnotationPreviouslyAutoImported), "Name", dc.GetName())
+		for i := range imported {
+			dc := &imported[i]
+			modifiedDC := dc.DeepCopy()
+
+			/*
+				Set annotation to true on DiscoveredCluster resource to prevent automatic import.
+				The user will need to request a reimport with the reimport annotation if they want the cluster
+				to be imported again automatically.
+			*/
+			if modifiedDC.Spec.ImportAsManagedCluster {
+				if modifiedDC.Annotations == nil {
+					modifiedDC.Annotations = map[string]string{}
 				}
-				modifiedDC.Spec.ImportAsManagedCluster = false
+				modifiedDC.Annotations[utils.AnnotationPreviouslyAutoImported] = "true"
 
-				if err := r.Patch(ctx, modifiedDC, client.MergeFrom(dc)); err != nil {
-					logf.Error(err, "failed to patch DiscoveredCluster", "Name", dc.GetName())
-					return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
-				}
-				break
+				logf.Info(fmt.Sprintf("Added '%v' annotation to DiscoveredCluster",
+					utils.AnnotationPreviouslyAutoImported), "Name", dc.GetName())
+			}
+			modifiedDC.Spec.ImportAsManagedCluster = false
+
+			/*
+				The annotation and importAsManagedCluster record user intent, so they are changed on behalf of the
+				user with a merge patch instead of being applied. If they were part of the apply configuration of
+				this controller, leaving them out of the next managed status apply would remove them again.
+			*/
+			if err := r.Patch(ctx, modifiedDC, client.MergeFrom(dc),
+				client.FieldOwner(managedClusterFieldOwner)); err != nil {
+				logf.Error(err, "failed to patch DiscoveredCluster", "Name", dc.GetName())
+				return ctrl.Result{RequeueAfter: recon.ErrorRefreshInterval}, err
 			}
 		}
 	}
@@ -124,7 +135,32 @@ func (r *ManagedClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager ...
+/*
+getImportedDiscoveredClusters returns the DiscoveredClusters that the ManagedCluster was imported for: those that
+recorded its name in their status, and the one whose UID is in its created-by label. The preferred name is not used,
+because a DiscoveredCluster that was never imported can have the same display name.
+*/
+func (r *ManagedClusterReconciler) getImportedDiscoveredClusters(ctx context.Context, mc *clusterapiv1.ManagedCluster,
+	discoveredClusters *discovery.DiscoveredClusterList) ([]discovery.DiscoveredCluster, error) {
+	imported := &discovery.DiscoveredClusterList{}
+	if err := r.List(ctx, imported, client.MatchingFields{managedClusterNameIndex: mc.GetName()}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list DiscoveredClusters using ManagedCluster name %s", mc.GetName())
+	}
+
+	if createdBy := mc.GetLabels()[utils.LabelCreatedBy]; createdBy != "" {
+		for _, dc := range discoveredClusters.Items {
+			if string(dc.UID) == createdBy && dc.Status.ManagedClusterName != mc.GetName() {
+				imported.Items = append(imported.Items, dc)
+			}
+		}
+	}
+	return imported.Items, nil
+}
+
+/*
+SetupWithManager sets up the controller with the Manager. DiscoveredClusters are looked up by the ManagedCluster name
+index, which is registered by the DiscoveredCluster controller.
+*/
 func (r *ManagedClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterapiv1.ManagedCluster{}, builder.OnlyMetadata).
@@ -154,28 +190,22 @@ func (r *ManagedClusterReconciler) updateManagedLabels(ctx context.Context,
 	}
 
 	for _, dc := range discoveredClusters.Items {
-		dc := dc
-		dcID := getDiscoveredID(dc)
-
-		if isManaged[dcID] {
-			if updateRequired := setManagedStatus(&dc); updateRequired {
-				// Update with managed labels
-				if err := r.Update(ctx, &dc); err != nil {
-					return errors.Wrapf(err, "error setting managed status `%s`", dc.Name)
-				}
-				logf.Info("Updated cluster, adding managed status", "discoveredcluster", dc.Name,
-					"discoveredcluster namespace", dc.Namespace)
-			}
+		managed := isManaged[getDiscoveredID(dc)]
+		if !managedStatusChanged(dc, managed) {
+			continue
+		}
+
+		if err := applyManagedStatus(ctx, r.Client, dc, managed); err != nil {
+			return err
+		}
 
+		if managed {
+			logf.Info("Updated cluster, adding managed status", "discoveredcluster", dc.Name,
+				"discoveredcluster namespace", dc.Namespace)
+			r.Recorder.Event(&dc, corev1.EventTypeNormal, EventReasonManaged, "Cluster is managed as a ManagedCluster")
 		} else {
-			if updateRequired := unsetManagedStatus(&dc); updateRequired {
-				// Update with managed labels removed
-				if err := r.Update(ctx, &dc); err != nil {
-					return errors.Wrapf(err, "error unsetting managed status `%s`", dc.Name)
-				}
-				logf.Info("Updated cluster, removing managed status", "discoveredcluster", dc.Name,
-					"discoveredcluster namespace", dc.Namespace)
-			}
+			logf.Info("Updated cluster, removing managed status", "discoveredcluster", dc.Name,
+				"discoveredcluster namespace", dc.Namespace)
 		}
 	}
 
@@ -15,10 +15,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	discovery "github.com/stolostron/discovery/api/v1"
+	utils "github.com/stolostron/discovery/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -28,7 +31,8 @@ const (
 )
 
 var mcr = &ManagedClusterReconciler{
-	Client: fake.NewClientBuilder().Build(),
+	Client:   fake.NewClientBuilder().Build(),
+	Recorder: &record.FakeRecorder{},
 }
 
 var (
@@ -336,6 +340,54 @@ func Test_unsetManagedStatus(t *testing.T) {
 	}
 }
 
+func Test_managedStatusChanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		dc      discovery.DiscoveredCluster
+		managed bool
+		want    bool
+	}{
+		{
+			name: "Managed status already set",
+			dc: discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"isManagedCluster": "true"}},
+				Spec:       discovery.DiscoveredClusterSpec{IsManagedCluster: true},
+			},
+			managed: true,
+			want:    false,
+		},
+		{
+			name: "Managed label missing",
+			dc: discovery.DiscoveredCluster{
+				Spec: discovery.DiscoveredClusterSpec{IsManagedCluster: true},
+			},
+			managed: true,
+			want:    true,
+		},
+		{
+			name: "Managed label left behind",
+			dc: discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"isManagedCluster": "true"}},
+			},
+			managed: false,
+			want:    true,
+		},
+		{
+			name:    "Not managed",
+			dc:      discovery.DiscoveredCluster{},
+			managed: false,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := managedStatusChanged(tt.dc, tt.managed); got != tt.want {
+				t.Errorf("managedStatusChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_ManagedCluster_Reconciler_Reconcile(t *testing.T) {
 	tests := []struct {
 		name string
@@ -355,6 +407,8 @@ func Test_ManagedCluster_Reconciler_Reconcile(t *testing.T) {
 	}
 
 	registerScheme()
+	mcr.Client = fake.NewClientBuilder().
+		WithIndex(&discovery.DiscoveredCluster{}, managedClusterNameIndex, indexManagedClusterName).Build()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if err := mcr.Create(context.TODO(), &mockCluster415); err != nil {
@@ -386,6 +440,79 @@ func Test_ManagedCluster_Reconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func Test_ManagedCluster_Reconciler_Reconcile_deleted(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		status discovery.DiscoveredClusterStatus
+	}{
+		{
+			name:   "should match the DiscoveredCluster by the ManagedCluster name in its status",
+			status: discovery.DiscoveredClusterStatus{ManagedClusterName: "prod-a1b2c"},
+		},
+		{
+			name:   "should match the DiscoveredCluster by the created-by label of the ManagedCluster",
+			labels: map[string]string{utils.LabelCreatedBy: "imported-uid"},
+		},
+	}
+
+	registerScheme()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "imported", Namespace: TestManagedNamespace, UID: "imported-uid"},
+				Spec: discovery.DiscoveredClusterSpec{
+					Name: "imported", DisplayName: "prod", ImportAsManagedCluster: true,
+				},
+				Status: tt.status,
+			}
+			unimported := &discovery.DiscoveredCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "unimported", Namespace: TestManagedNamespace, UID: "other-uid"},
+				Spec: discovery.DiscoveredClusterSpec{
+					Name: "unimported", DisplayName: "prod-a1b2c", ImportAsManagedCluster: true,
+				},
+			}
+			mc := &clusterapiv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "prod-a1b2c",
+					Labels:     tt.labels,
+					Finalizers: []string{discovery.ImportCleanUpFinalizer},
+				},
+			}
+
+			mr := &ManagedClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(imported, unimported, mc).
+					WithStatusSubresource(&discovery.DiscoveredCluster{}).
+					WithIndex(&discovery.DiscoveredCluster{}, managedClusterNameIndex, indexManagedClusterName).Build(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			if err := mr.Delete(context.TODO(), mc); err != nil {
+				t.Fatalf("failed to delete ManagedCluster: %v", err)
+			}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: mc.GetName()}}
+			if _, err := mr.Reconcile(context.TODO(), req); err != nil {
+				t.Fatalf("failed to reconcile ManagedCluster: %v", err)
+			}
+
+			got := &discovery.DiscoveredCluster{}
+			if err := mr.Get(context.TODO(), client.ObjectKeyFromObject(imported), got); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if got.Spec.ImportAsManagedCluster || got.Annotations[utils.AnnotationPreviouslyAutoImported] != "true" {
+				t.Errorf("imported DiscoveredCluster was not marked as previously auto-imported")
+			}
+
+			if err := mr.Get(context.TODO(), client.ObjectKeyFromObject(unimported), got); err != nil {
+				t.Fatalf("failed to get DiscoveredCluster: %v", err)
+			}
+			if !got.Spec.ImportAsManagedCluster || got.Annotations[utils.AnnotationPreviouslyAutoImported] != "" {
+				t.Errorf("DiscoveredCluster with the same display name was marked as previously auto-imported")
+			}
+		})
+	}
+}
+
 func newManagedCluster(name, clusterID string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
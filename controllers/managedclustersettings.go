@@ -0,0 +1,138 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	klusterletconfigv1alpha1 "github.com/stolostron/cluster-lifecycle-api/klusterletconfig/v1alpha1"
+	discovery "github.com/stolostron/discovery/api/v1"
+	utils "github.com/stolostron/discovery/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+/*
+getManagedClusterSettings returns the settings of the ManagedCluster created to import the DiscoveredCluster. The
+settings of the DiscoveryConfig are overridden by the DiscoveryImportPolicy that triggered the import, which are in turn
+overridden by the settings of the DiscoveredCluster. Labels and annotations are merged.
+*/
+func (r *DiscoveredClusterReconciler) getManagedClusterSettings(ctx context.Context,
+	dc discovery.DiscoveredCluster) discovery.ManagedClusterSettings {
+	settings := discovery.ManagedClusterSettings{}
+
+	if config := r.getDiscoveryConfig(ctx, dc.Namespace); config != nil {
+		mergeManagedClusterSettings(&settings, config.Spec.ManagedCluster)
+	}
+
+	if policy := r.getImportPolicy(ctx, dc); policy != nil {
+		mergeManagedClusterSettings(&settings, &discovery.ManagedClusterSettings{
+			ClusterSet: policy.Spec.ManagedClusterSet,
+			Labels:     policy.Spec.ManagedClusterLabels,
+		})
+	}
+
+	mergeManagedClusterSettings(&settings, dc.Spec.ManagedCluster)
+	return settings
+}
+
+// mergeManagedClusterSettings merges the overrides into the settings. The overrides may be nil.
+func mergeManagedClusterSettings(settings, overrides *discovery.ManagedClusterSettings) {
+	if overrides == nil {
+		return
+	}
+
+	if overrides.ClusterSet != "" {
+		settings.ClusterSet = overrides.ClusterSet
+	}
+
+	if overrides.KlusterletConfig != "" {
+		settings.KlusterletConfig = overrides.KlusterletConfig
+	}
+
+	for k, v := range overrides.Labels {
+		if settings.Labels == nil {
+			settings.Labels = map[string]string{}
+		}
+		settings.Labels[k] = v
+	}
+
+	for k, v := range overrides.Annotations {
+		if settings.Annotations == nil {
+			settings.Annotations = map[string]string{}
+		}
+		settings.Annotations[k] = v
+	}
+}
+
+// getClusterSet returns the ManagedClusterSet of the settings, or the default ManagedClusterSet if it is not set.
+func getClusterSet(settings discovery.ManagedClusterSettings) string {
+	if settings.ClusterSet == "" {
+		return DefaultName
+	}
+	return settings.ClusterSet
+}
+
+/*
+applyManagedClusterSettings sets the ManagedClusterSet, KlusterletConfig, labels and annotations of the settings on the
+ManagedCluster, so that Placements select the cluster as soon as it is created. Labels and annotations never replace the
+name label, the labels that identify the resources created by the discovery operator, or the annotations set by the
+operator.
+*/
+func applyManagedClusterSettings(mc *clusterapiv1.ManagedCluster, settings discovery.ManagedClusterSettings) {
+	reservedLabels := map[string]bool{
+		utils.LabelName:                    true,
+		utils.LabelCreatedBy:               true,
+		utils.LabelHypershiftDiscoveryType: true,
+		utils.LabelClusterSet:              true,
+	}
+
+	if mc.Labels == nil {
+		mc.Labels = map[string]string{}
+	}
+	for k, v := range settings.Labels {
+		if reservedLabels[k] {
+			logf.Info("Ignoring reserved ManagedCluster label", "Name", mc.Name, "Label", k)
+			continue
+		}
+		mc.Labels[k] = v
+	}
+	mc.Labels[utils.LabelClusterSet] = getClusterSet(settings)
+
+	if mc.Annotations == nil {
+		mc.Annotations = map[string]string{}
+	}
+	if settings.KlusterletConfig != "" {
+		mc.Annotations[utils.AnnotationKlusterletConfig] = settings.KlusterletConfig
+	}
+	for k, v := range settings.Annotations {
+		if _, found := mc.Annotations[k]; found {
+			logf.Info("Ignoring reserved ManagedCluster annotation", "Name", mc.Name, "Annotation", k)
+			continue
+		}
+		mc.Annotations[k] = v
+	}
+}
+
+/*
+getMissingKlusterletConfig returns the name of the KlusterletConfig of the settings if it does not exist, or an empty
+string if no KlusterletConfig is set or it exists. KlusterletConfigs do not exist when their CRD is not installed.
+*/
+func (r *DiscoveredClusterReconciler) getMissingKlusterletConfig(ctx context.Context,
+	settings discovery.ManagedClusterSettings) (string, error) {
+	if settings.KlusterletConfig == "" {
+		return "", nil
+	}
+
+	kc := &klusterletconfigv1alpha1.KlusterletConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: settings.KlusterletConfig}, kc); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return settings.KlusterletConfig, nil
+		}
+		return "", errors.Wrapf(err, "failed to get KlusterletConfig %s", settings.KlusterletConfig)
+	}
+	return "", nil
+}
@@ -0,0 +1,202 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// nameSuffixLength is the length of the cluster ID suffix added to ManagedCluster names that are already taken.
+	nameSuffixLength = 8
+
+	// managedClusterIDLabel is the label holding the cluster ID of a ManagedCluster.
+	managedClusterIDLabel = "clusterID"
+
+	// managedClusterNameIndex is the field index of DiscoveredClusters by the ManagedCluster name in their status.
+	managedClusterNameIndex = "status.managedClusterName"
+)
+
+// indexManagedClusterName returns the ManagedCluster name recorded in the status of a DiscoveredCluster.
+func indexManagedClusterName(obj client.Object) []string {
+	dc, ok := obj.(*discovery.DiscoveredCluster)
+	if !ok || dc.Status.ManagedClusterName == "" {
+		return nil
+	}
+	return []string{dc.Status.ManagedClusterName}
+}
+
+// getClusterIDSuffix returns the suffix derived from the cluster ID that makes the ManagedCluster name unique.
+func getClusterIDSuffix(dc discovery.DiscoveredCluster) string {
+	id := dc.Spec.RHOCMClusterID
+	if id == "" {
+		id = dc.Spec.Name
+	}
+	return discovery.NormalizeName(id, nameSuffixLength)
+}
+
+/*
+getManagedClusterNameCandidates returns the names that the ManagedCluster of the DiscoveredCluster may use, in order of
+preference: the name recorded in the status, the display name, and the display name suffixed with the cluster ID.
+MultiClusterEngineHCP clusters are not suffixed, because their ManagedCluster must be named after the hosted cluster,
+and neither are clusters that opted out with the ManagedClusterNameSuffixAnnotation.
+*/
+func getManagedClusterNameCandidates(dc discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig) (preferred string, candidates []string) {
+	preferred = discovery.GetPreferredManagedClusterName(&dc)
+
+	if dc.Status.ManagedClusterName != "" {
+		candidates = append(candidates, dc.Status.ManagedClusterName)
+	}
+	candidates = append(candidates, preferred)
+
+	if suffix := getClusterIDSuffix(dc); suffix != "" && dc.Spec.Type != "MultiClusterEngineHCP" &&
+		discovery.IsManagedClusterNameSuffixEnabled(&dc, config) {
+		base := discovery.NormalizeName(preferred, discovery.MaxManagedClusterNameLength-len(suffix)-1)
+		candidates = append(candidates, base+"-"+suffix)
+	}
+	return preferred, candidates
+}
+
+// getNameReason returns the reason of the NameConflict condition of a cluster that uses the available name.
+func getNameReason(name, preferred string) string {
+	if name == preferred {
+		return discovery.ReasonNameAvailable
+	}
+	return discovery.ReasonNameSuffixed
+}
+
+/*
+resolveManagedClusterName returns a name for the ManagedCluster of the DiscoveredCluster that is not used by another
+cluster, together with the reason of its NameConflict condition. The name is empty if no candidate is available. Once
+the ManagedCluster created for the cluster exists under the name recorded in the status, that name is kept without
+checking the other clusters.
+*/
+func (r *DiscoveredClusterReconciler) resolveManagedClusterName(ctx context.Context, dc discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig) (string, string, error) {
+	preferred, candidates := getManagedClusterNameCandidates(dc, config)
+
+	if recorded := dc.Status.ManagedClusterName; recorded != "" {
+		mc := &clusterapiv1.ManagedCluster{}
+		if err := r.Get(ctx, types.NamespacedName{Name: recorded}, mc); err == nil && isCreatedBy(mc, dc) {
+			return recorded, getNameReason(recorded, preferred), nil
+		} else if err != nil && !apierrors.IsNotFound(err) {
+			return "", "", errors.Wrapf(err, "failed to get ManagedCluster %s", recorded)
+		}
+	}
+
+	for _, name := range candidates {
+		available, err := r.isManagedClusterNameAvailable(ctx, dc, name)
+		if err != nil {
+			return "", "", err
+		}
+		if available {
+			return name, getNameReason(name, preferred), nil
+		}
+	}
+	return "", discovery.ReasonNameUnavailable, nil
+}
+
+/*
+isManagedClusterNameAvailable returns true if no other cluster uses the name. A name is used by another cluster when a
+ManagedCluster with the name was created for another cluster, or another DiscoveredCluster with a different cluster ID
+recorded it in its status.
+*/
+func (r *DiscoveredClusterReconciler) isManagedClusterNameAvailable(ctx context.Context, dc discovery.DiscoveredCluster,
+	name string) (bool, error) {
+	mc := &clusterapiv1.ManagedCluster{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, mc); err == nil {
+		if !isCreatedBy(mc, dc) && mc.GetLabels()[managedClusterIDLabel] != dc.Spec.Name {
+			return false, nil
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "failed to get ManagedCluster %s", name)
+	}
+
+	discoveredClusters := &discovery.DiscoveredClusterList{}
+	if err := r.List(ctx, discoveredClusters, client.MatchingFields{managedClusterNameIndex: name}); err != nil {
+		return false, errors.Wrapf(err, "failed to list DiscoveredClusters using ManagedCluster name %s", name)
+	}
+
+	for _, other := range discoveredClusters.Items {
+		if other.UID != dc.UID && other.Spec.Name != dc.Spec.Name && other.Status.ManagedClusterName == name {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+/*
+ensureManagedClusterName resolves the name of the ManagedCluster of the DiscoveredCluster and records it in the status
+before any import resource is created, so that the name stays stable once the import has started.
+*/
+func (r *DiscoveredClusterReconciler) ensureManagedClusterName(ctx context.Context, dc *discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig) error {
+	name, _, err := r.resolveManagedClusterName(ctx, *dc, config)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.Errorf("no unique ManagedCluster name is available for DiscoveredCluster %s", dc.Name)
+	}
+
+	if dc.Status.ManagedClusterName == name {
+		return nil
+	}
+
+	patch := client.MergeFrom(dc.DeepCopy())
+	dc.Status.ManagedClusterName = name
+	if err := r.Status().Patch(ctx, dc, patch); err != nil {
+		// If resource was deleted, ignore the error
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to record ManagedCluster name of DiscoveredCluster %s", dc.Name)
+	}
+	return nil
+}
+
+/*
+buildNameConflictCondition constructs the NameConflict condition of a DiscoveredCluster that is imported. The condition
+is True when the ManagedCluster cannot use the display name of the cluster.
+*/
+func (r *DiscoveredClusterReconciler) buildNameConflictCondition(ctx context.Context, dc *discovery.DiscoveredCluster,
+	config *discovery.DiscoveryConfig, now time.Time) discovery.DiscoveredClusterCondition {
+	condition := discovery.DiscoveredClusterCondition{
+		Type:               discovery.ConditionNameConflict,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: dc.Generation,
+	}
+
+	name, reason, err := r.resolveManagedClusterName(ctx, *dc, config)
+	if err != nil {
+		logf.Error(err, "Failed to resolve ManagedCluster name", "Name", dc.Name)
+		name, reason = discovery.GetManagedClusterName(dc), discovery.ReasonNameAvailable
+	}
+
+	preferred, _ := getManagedClusterNameCandidates(*dc, config)
+	condition.Reason = reason
+	switch reason {
+	case discovery.ReasonNameSuffixed:
+		condition.Status = metav1.ConditionTrue
+		condition.Message = fmt.Sprintf("ManagedCluster name %s is used by another cluster, using %s instead",
+			preferred, name)
+	case discovery.ReasonNameUnavailable:
+		condition.Status = metav1.ConditionTrue
+		condition.Message = fmt.Sprintf("ManagedCluster name %s is used by another cluster", preferred)
+	default:
+		condition.Message = fmt.Sprintf("ManagedCluster name %s is available", name)
+	}
+	return condition
+}
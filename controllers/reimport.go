@@ -0,0 +1,136 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	discovery "github.com/stolostron/discovery/api/v1"
+	utils "github.com/stolostron/discovery/util"
+	recon "github.com/stolostron/discovery/util/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterapiv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+/*
+getReimportRequest returns the time of the reimport request of the DiscoveredCluster, or nil if there is no request or
+it has already been processed. An error is returned if the reimport annotation is not a valid RFC 3339 timestamp.
+*/
+func getReimportRequest(dc *discovery.DiscoveredCluster) (*metav1.Time, error) {
+	value, found := dc.GetAnnotations()[discovery.ReimportAnnotation]
+	if !found {
+		return nil, nil
+	}
+
+	requestedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation", discovery.ReimportAnnotation)
+	}
+
+	if history := dc.Status.ReimportHistory; len(history) > 0 &&
+		!requestedAt.After(history[len(history)-1].RequestedAt.Time) {
+		return nil, nil
+	}
+	return &metav1.Time{Time: requestedAt}, nil
+}
+
+/*
+getReimportBlocker returns why the DiscoveredCluster cannot be reimported yet, or an empty string if it can. A cluster
+is reimported once its previous ManagedCluster and the namespace of the ManagedCluster are fully deleted.
+*/
+func (r *DiscoveredClusterReconciler) getReimportBlocker(ctx context.Context,
+	dc *discovery.DiscoveredCluster) (string, error) {
+	if dc.Spec.IsManagedCluster {
+		return "the cluster is still managed", nil
+	}
+
+	name := discovery.GetManagedClusterName(dc)
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, &clusterapiv1.ManagedCluster{}); err == nil {
+		return "ManagedCluster " + name + " still exists", nil
+	} else if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return "", errors.Wrapf(err, "failed to get ManagedCluster %s", name)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, ns); err == nil {
+		if !ns.GetDeletionTimestamp().IsZero() {
+			return "Namespace " + name + " is being deleted", nil
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return "", errors.Wrapf(err, "failed to get Namespace %s", name)
+	}
+	return "", nil
+}
+
+/*
+handleReimport processes the reimport request of the DiscoveredCluster. Once the previous ManagedCluster is fully
+deleted, the guard that prevents the cluster from being imported again is cleared, the import intent is restored and
+the reimport is recorded in the status. A request whose import was already restarted, because recording it failed
+before, is only recorded. The time after which a pending request is checked again is returned.
+*/
+func (r *DiscoveredClusterReconciler) handleReimport(ctx context.Context, dc *discovery.DiscoveredCluster,
+	strategy string, now time.Time) (time.Duration, error) {
+	requestedAt, err := getReimportRequest(dc)
+	if err != nil {
+		logf.Error(err, "Ignoring reimport request", "Name", dc.Name)
+		r.Recorder.Eventf(dc, corev1.EventTypeWarning, EventReasonReimportPending, "Reimport request ignored: %v", err)
+		return 0, nil
+	}
+	if requestedAt == nil {
+		return 0, nil
+	}
+
+	if strategy == discovery.ImportStrategyDisabled {
+		logf.Info("Import is disabled. Skipping reimport.", "Name", dc.Spec.DisplayName)
+		return 0, nil
+	}
+
+	restarted := dc.Spec.ImportAsManagedCluster && !utils.IsAnnotationTrue(dc, utils.AnnotationPreviouslyAutoImported)
+	if !restarted {
+		blocker, err := r.getReimportBlocker(ctx, dc)
+		if err != nil {
+			return 0, err
+		}
+		if blocker != "" {
+			logf.Info("Waiting to reimport DiscoveredCluster", "Name", dc.Spec.DisplayName, "Reason", blocker)
+			r.Recorder.Eventf(dc, corev1.EventTypeNormal, EventReasonReimportPending, "Reimport pending: %s", blocker)
+			return recon.WarningRefreshInterval, nil
+		}
+
+		patch := client.MergeFrom(dc.DeepCopy())
+		delete(dc.Annotations, utils.AnnotationPreviouslyAutoImported)
+		dc.Spec.ImportAsManagedCluster = true
+		if err := r.Patch(ctx, dc, patch); err != nil {
+			return 0, errors.Wrapf(err, "failed to restart import of DiscoveredCluster %s", dc.Name)
+		}
+	}
+
+	statusPatch := client.MergeFrom(dc.DeepCopy())
+	dc.Status.ReimportHistory = append(dc.Status.ReimportHistory, discovery.ReimportRecord{
+		RequestedAt:        *requestedAt,
+		StartedAt:          metav1.NewTime(now),
+		ManagedClusterName: discovery.GetManagedClusterName(dc),
+	})
+	if n := len(dc.Status.ReimportHistory); n > discovery.MaxReimportHistory {
+		dc.Status.ReimportHistory = dc.Status.ReimportHistory[n-discovery.MaxReimportHistory:]
+	}
+	if err := r.Status().Patch(ctx, dc, statusPatch); err != nil && !apierrors.IsNotFound(err) {
+		return 0, errors.Wrapf(err, "failed to record reimport of DiscoveredCluster %s", dc.Name)
+	}
+
+	if restarted {
+		logf.Info("Recorded reimport of DiscoveredCluster", "Name", dc.Spec.DisplayName)
+		return 0, nil
+	}
+
+	logf.Info("Reimporting DiscoveredCluster", "Name", dc.Spec.DisplayName)
+	r.Recorder.Event(dc, corev1.EventTypeNormal, EventReasonReimported, "Import restarted by reimport request")
+	return 0, nil
+}
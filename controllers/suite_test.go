@@ -91,16 +91,22 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	_, err = (&DiscoveryConfigReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Recorder: k8sManager.GetEventRecorderFor("discoveryconfig-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = k8sManager.GetFieldIndexer().IndexField(context.Background(), &v1.DiscoveredCluster{},
+		managedClusterNameIndex, indexManagedClusterName)
+	Expect(err).ToNot(HaveOccurred())
+
 	events := make(chan event.GenericEvent)
 	err = (&ManagedClusterReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Trigger: events,
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Trigger:  events,
+		Recorder: k8sManager.GetEventRecorderFor("managedcluster-controller"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -24,6 +24,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -41,12 +42,13 @@ import (
 	"go.uber.org/zap/zapcore"
 	admissionregistration "k8s.io/api/admissionregistration/v1"
 	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
@@ -65,8 +67,11 @@ import (
 
 	klusterletconfigv1alpha1 "github.com/stolostron/cluster-lifecycle-api/klusterletconfig/v1alpha1"
 	discoveryv1 "github.com/stolostron/discovery/api/v1"
+	discoveryv1alpha1 "github.com/stolostron/discovery/api/v1alpha1"
 	"github.com/stolostron/discovery/controllers"
+	"github.com/stolostron/discovery/pkg/certificate"
 	"github.com/stolostron/discovery/pkg/ocm"
+	"github.com/stolostron/discovery/pkg/platform"
 	"github.com/stolostron/discovery/pkg/version"
 	agentv1 "github.com/stolostron/klusterlet-addon-controller/pkg/apis/agent/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -77,6 +82,13 @@ import (
 const (
 	crdName = "discoveredclusters.discovery.open-cluster-management.io"
 
+	// injectCABundleAnnotation requests the OpenShift service CA to inject its CA bundle into the resource.
+	injectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+
+	// webhookServiceName is the name of the Service of the webhook server. Its serving certificate is stored in a
+	// Secret of the same name.
+	webhookServiceName = "discovery-operator-webhook-service"
+
 	// webhookRetryDelay is the delay between retries when webhook operations fail.
 	// Set to 5 seconds to balance between quick recovery from transient failures
 	// and avoiding overwhelming the API server.
@@ -85,6 +97,12 @@ const (
 	// webhookMaxAttempts is the maximum number of retry attempts for webhook operations.
 	// With retryDelay=5s and maxAttempts=10, total retry time is ~50 seconds.
 	webhookMaxAttempts = 10
+
+	/*
+		operatorConditionEnv is the environment variable that OLM sets in the deployments of the operators it installs.
+		OLM configures the conversion webhooks declared in the ClusterServiceVersion of these operators.
+	*/
+	operatorConditionEnv = "OPERATOR_CONDITION_NAME"
 )
 
 var (
@@ -104,6 +122,7 @@ func init() {
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(configv1.AddToScheme(scheme))
 	utilruntime.Must(discoveryv1.AddToScheme(scheme))
+	utilruntime.Must(discoveryv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(agentv1.SchemeBuilder.AddToScheme(scheme))
 	utilruntime.Must(klusterletconfigv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(clusterapiv1beta1.AddToScheme(scheme))
@@ -120,6 +139,13 @@ func main() {
 	var leaseDuration time.Duration
 	var renewDeadline time.Duration
 	var retryPeriod time.Duration
+	var maxConcurrentImports int
+	var importsPerMinute int
+	var importQueueOrdering string
+	var selfManagedWebhookCerts bool
+	var tlsProfileType string
+	var tlsMinVersion string
+	var tlsCipherSuites string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -138,6 +164,25 @@ func main() {
 	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 26*time.Second, ""+
 		"The duration the clients should wait between attempting acquisition and renewal "+
 		"of a leadership. This is only applicable if leader election is enabled.")
+	flag.IntVar(&maxConcurrentImports, "max-concurrent-imports", 0,
+		"The maximum number of DiscoveredClusters imported at the same time. Imports are not limited when 0.")
+	flag.IntVar(&importsPerMinute, "imports-per-minute", 0,
+		"The maximum number of DiscoveredCluster imports started per minute. Imports are not limited when 0.")
+	flag.StringVar(&importQueueOrdering, "import-queue-ordering", controllers.ImportQueueOrderingFIFO,
+		"The order in which queued DiscoveredClusters are imported, FIFO or Priority. With Priority, clusters with a "+
+			"higher import-priority annotation are imported first.")
+	flag.BoolVar(&selfManagedWebhookCerts, "self-managed-webhook-certs", false,
+		"Generate and rotate the webhook serving certificate and its CA, and inject the CA bundle into the webhook "+
+			"configurations, instead of relying on the OpenShift service CA.")
+	flag.StringVar(&tlsProfileType, "tls-profile", string(configv1.TLSProfileIntermediateType),
+		"The TLS profile of the webhook server when the cluster has no OpenShift APIServer resource: Old, "+
+			"Intermediate, Modern or Custom.")
+	flag.StringVar(&tlsMinVersion, "tls-min-version", "",
+		"The minimum TLS version of the webhook server, e.g. VersionTLS12, overriding the one of the TLS profile when "+
+			"the cluster has no OpenShift APIServer resource.")
+	flag.StringVar(&tlsCipherSuites, "tls-cipher-suites", "",
+		"A comma separated list of cipher suites in OpenSSL format, overriding the ones of the TLS profile when the "+
+			"cluster has no OpenShift APIServer resource.")
 	opts := zap.Options{
 		Development: true,
 		TimeEncoder: zapcore.ISO8601TimeEncoder,
@@ -161,17 +206,52 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Get TLS configuration from OpenShift APIServer profile
-	tlsProfile, err := ocm.GetAPIServerTLSProfile(ctx, uncachedClient)
-	if err != nil || tlsProfile == nil {
-		setupLog.Error(err, "unable to get APIServer TLS profile, using default Intermediate profile")
-		tlsProfile = configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+	// Detect the optional OpenShift and OCM APIs, so that the operator also runs on clusters that do not serve them
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(ctrl.GetConfigOrDie())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	capabilities, err := platform.Detect(discoveryClient, platform.OptionalAPIs...)
+	if err != nil {
+		setupLog.Error(err, "unable to detect the APIs served by the cluster")
+		os.Exit(1)
+	}
+
+	tlsProfile, err := ocm.GetFlagTLSProfile(tlsProfileType, tlsMinVersion,
+		strings.FieldsFunc(tlsCipherSuites, func(r rune) bool { return r == ',' }))
+	if err != nil {
+		setupLog.Error(err, "invalid TLS profile flags")
+		os.Exit(1)
+	}
+
+	// Get TLS configuration from OpenShift APIServer profile, falling back to the TLS profile flags
+	if capabilities.Has(platform.APIServer) {
+		apiServerProfile, err := ocm.GetAPIServerTLSProfile(ctx, uncachedClient)
+		if err != nil || apiServerProfile == nil {
+			setupLog.Error(err, "unable to get APIServer TLS profile, using the TLS profile flags",
+				"profile", tlsProfileType)
+		} else {
+			tlsProfile = apiServerProfile
+		}
+	} else {
+		setupLog.Info("APIServer resource is not served by the cluster, using the TLS profile flags",
+			"profile", tlsProfileType)
 	}
 
 	minTLSVersion := ocm.ConvertTLSVersion(tlsProfile.MinTLSVersion)
 	cipherSuites := ocm.ConvertCipherSuites(tlsProfile.Ciphers)
 	setupLog.Info("Configuring webhook server TLS", "minTLSVersion", tlsProfile.MinTLSVersion, "numericValue", minTLSVersion, "cipherCount", len(cipherSuites))
 
+	var certManager *certificate.Manager
+	if selfManagedWebhookCerts && os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		certManager, err = ensureWebhookCertificates(ctx, uncachedClient)
+		if err != nil {
+			setupLog.Error(err, "unable to ensure webhook certificates")
+			os.Exit(1)
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
@@ -186,6 +266,10 @@ func main() {
 				if minTLSVersion < tls.VersionTLS13 && len(cipherSuites) > 0 {
 					config.CipherSuites = cipherSuites
 				}
+				// Serve the self-managed certificate from memory, so that its rotations are picked up without a restart
+				if certManager != nil {
+					config.GetCertificate = certManager.GetCertificate
+				}
 			}},
 		}),
 		HealthProbeBindAddress: probeAddr,
@@ -203,8 +287,10 @@ func main() {
 	events := make(chan event.GenericEvent)
 
 	discoveryConfigReconciler := &controllers.DiscoveryConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("discoveryconfig-controller"),
+		Capabilities: capabilities,
 	}
 	discoveryConfigController, err = discoveryConfigReconciler.SetupWithManager(mgr)
 	if err != nil {
@@ -212,18 +298,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	var importQueue *controllers.ImportQueue
+	if maxConcurrentImports > 0 || importsPerMinute > 0 {
+		if importQueueOrdering != controllers.ImportQueueOrderingFIFO &&
+			importQueueOrdering != controllers.ImportQueueOrderingPriority {
+			setupLog.Error(fmt.Errorf("invalid import queue ordering %q", importQueueOrdering),
+				"import-queue-ordering must be FIFO or Priority")
+			os.Exit(1)
+		}
+		importQueue = controllers.NewImportQueue(maxConcurrentImports, importsPerMinute, importQueueOrdering)
+	}
+
 	if err = (&controllers.DiscoveredClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("discoveredcluster-controller"),
+		ImportQueue:  importQueue,
+		Capabilities: capabilities,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, ControllerError, "controller", "DiscoveredCluster")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ManagedClusterReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Trigger: events,
+	if !capabilities.Has(platform.ManagedCluster) {
+		setupLog.Info("ManagedCluster API is not served by the cluster, skipping controller", "controller",
+			"ManagedCluster")
+	} else if err = (&controllers.ManagedClusterReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Trigger:  events,
+		Recorder: mgr.GetEventRecorderFor("managedcluster-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, ControllerError, "controller", "ManagedCluster")
 		os.Exit(1)
@@ -233,15 +337,34 @@ func main() {
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		// https://book.kubebuilder.io/cronjob-tutorial/running.html#running-webhooks-locally
 		// https://book.kubebuilder.io/multiversion-tutorial/webhooks.html#and-maingo
-		if err = ensureWebhooks(uncachedClient); err != nil {
+		var caBundle []byte
+		if certManager != nil {
+			caBundle = certManager.CABundle()
+		}
+		if err = ensureWebhooks(uncachedClient, caBundle); err != nil {
 			setupLog.Error(err, "unable to ensure webhook", "webhook", "DiscoveredCluster")
 			os.Exit(1)
 		}
 
+		if certManager != nil {
+			certManager.OnRotate = func(ctx context.Context, caBundle []byte) error {
+				return applyWebhooks(ctx, uncachedClient, certManager.Namespace, caBundle)
+			}
+			if err = mgr.Add(certManager); err != nil {
+				setupLog.Error(err, "unable to add webhook certificate manager")
+				os.Exit(1)
+			}
+		}
+
 		if err = (&discoveryv1.DiscoveredCluster{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "DiscoveredCluster")
 			os.Exit(1)
 		}
+
+		if err = (&discoveryv1.DiscoveryConfig{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DiscoveryConfig")
+			os.Exit(1)
+		}
 	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -262,73 +385,178 @@ func main() {
 	}
 }
 
-func ensureWebhooks(k8sClient client.Client) error {
-	ctx := context.Background()
-
+func ensureWebhooks(k8sClient client.Client, caBundle []byte) error {
 	deploymentNamespace, ok := os.LookupEnv("POD_NAMESPACE")
 	if !ok {
 		setupLog.Info("Failing due to being unable to locate webhook service namespace")
 		os.Exit(1)
 	}
-	validatingWebhook := discoveryv1.ValidatingWebhook(deploymentNamespace)
 
 	for i := 0; i < webhookMaxAttempts; i++ {
-		setupLog.Info("Applying ValidatingWebhookConfiguration")
-
-		// Get reference to DiscoveredCluster CRD to set as owner of the webhook
-		// This way if the CRD is deleted the webhook will be removed with it
-		crdKey := types.NamespacedName{Name: crdName}
-		owner := &apixv1.CustomResourceDefinition{}
-		if err := k8sClient.Get(context.Background(), crdKey, owner); err != nil {
-			setupLog.Error(err, "Failed to get DiscoveredCluster CRD")
+		setupLog.Info("Applying webhook configurations")
+		if err := applyWebhooks(context.Background(), k8sClient, deploymentNamespace, caBundle); err != nil {
+			setupLog.Error(err, "Error applying webhook configurations")
 			time.Sleep(webhookRetryDelay)
 			continue
 		}
-		validatingWebhook.SetOwnerReferences([]metav1.OwnerReference{
-			{
-				APIVersion: "apiextensions.k8s.io/v1",
-				Kind:       "CustomResourceDefinition",
-				Name:       owner.Name,
-				UID:        owner.UID,
-			},
-		})
-
-		existingWebhook := &admissionregistration.ValidatingWebhookConfiguration{}
-		existingWebhook.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "admissionregistration.k8s.io",
-			Version: "v1",
-			Kind:    "ValidatingWebhookConfiguration",
-		})
-		err := k8sClient.Get(ctx, types.NamespacedName{Name: validatingWebhook.GetName()}, existingWebhook)
-		if err != nil && errors.IsNotFound(err) {
-			// Webhook not found. Create and return
-			err = k8sClient.Create(ctx, validatingWebhook)
-			if err != nil {
-				setupLog.Error(err, "Error creating validatingwebhookconfiguration")
-				time.Sleep(webhookRetryDelay)
-				continue
-			}
-			return nil
+		return nil
+	}
+	return fmt.Errorf("unable to ensure webhooks exist in allotted time")
+}
 
-		} else if err != nil {
-			setupLog.Error(err, "Error getting validatingwebhookconfiguration")
+/*
+ensureWebhookCertificates returns a certificate manager for the webhook service, after loading or generating its
+certificates. The certificates are stored in the Secret that the OpenShift service CA would otherwise create.
+*/
+func ensureWebhookCertificates(ctx context.Context, k8sClient client.Client) (*certificate.Manager, error) {
+	deploymentNamespace, ok := os.LookupEnv("POD_NAMESPACE")
+	if !ok {
+		return nil, fmt.Errorf("unable to locate webhook service namespace")
+	}
+
+	certManager := certificate.NewManager(k8sClient, deploymentNamespace, webhookServiceName, webhookServiceName)
+	for i := 0; i < webhookMaxAttempts; i++ {
+		if err := certManager.Ensure(ctx); err != nil {
+			setupLog.Error(err, "Error ensuring webhook certificates")
 			time.Sleep(webhookRetryDelay)
 			continue
+		}
+		return certManager, nil
+	}
+	return nil, fmt.Errorf("unable to ensure webhook certificates in allotted time")
+}
+
+/*
+applyWebhooks applies the validating, mutating and conversion webhook configurations of the operator. When caBundle is
+set, it is injected into the webhook configurations instead of relying on the service CA of OpenShift. The conversion
+webhooks are left to OLM when it installed the operator.
+*/
+func applyWebhooks(ctx context.Context, k8sClient client.Client, namespace string, caBundle []byte) error {
+	validatingWebhook := discoveryv1.ValidatingWebhook(namespace)
+	mutatingWebhook := discoveryv1.MutatingWebhook(namespace)
+	if caBundle != nil {
+		delete(validatingWebhook.Annotations, injectCABundleAnnotation)
+		for i := range validatingWebhook.Webhooks {
+			validatingWebhook.Webhooks[i].ClientConfig.CABundle = caBundle
+		}
+		delete(mutatingWebhook.Annotations, injectCABundleAnnotation)
+		for i := range mutatingWebhook.Webhooks {
+			mutatingWebhook.Webhooks[i].ClientConfig.CABundle = caBundle
+		}
+	}
+
+	// Get reference to DiscoveredCluster CRD to set as owner of the webhook
+	// This way if the CRD is deleted the webhook will be removed with it
+	crdKey := types.NamespacedName{Name: crdName}
+	owner := &apixv1.CustomResourceDefinition{}
+	if err := k8sClient.Get(ctx, crdKey, owner); err != nil {
+		return fmt.Errorf("failed to get DiscoveredCluster CRD: %w", err)
+	}
+	ownerReferences := []metav1.OwnerReference{
+		{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+			Name:       owner.Name,
+			UID:        owner.UID,
+		},
+	}
+	validatingWebhook.SetOwnerReferences(ownerReferences)
+	mutatingWebhook.SetOwnerReferences(ownerReferences)
+
+	existingValidatingWebhook := &admissionregistration.ValidatingWebhookConfiguration{}
+	if err := applyWebhookConfiguration(ctx, k8sClient, validatingWebhook, existingValidatingWebhook, func() {
+		existingValidatingWebhook.Webhooks = validatingWebhook.Webhooks
+		if caBundle != nil {
+			delete(existingValidatingWebhook.Annotations, injectCABundleAnnotation)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to apply validatingwebhookconfiguration: %w", err)
+	}
+
+	existingMutatingWebhook := &admissionregistration.MutatingWebhookConfiguration{}
+	if err := applyWebhookConfiguration(ctx, k8sClient, mutatingWebhook, existingMutatingWebhook, func() {
+		existingMutatingWebhook.Webhooks = mutatingWebhook.Webhooks
+		if caBundle != nil {
+			delete(existingMutatingWebhook.Annotations, injectCABundleAnnotation)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to apply mutatingwebhookconfiguration: %w", err)
+	}
+
+	if _, found := os.LookupEnv(operatorConditionEnv); found {
+		return nil
+	}
+
+	if err := applyConversionWebhooks(ctx, k8sClient, namespace, caBundle); err != nil {
+		return fmt.Errorf("failed to apply conversion webhooks: %w", err)
+	}
+	return nil
+}
 
-		} else if err == nil {
-			// Webhook already exists. Update and return
-			setupLog.Info("Updating existing validatingwebhookconfiguration")
-			existingWebhook.Webhooks = validatingWebhook.Webhooks
-			err = k8sClient.Update(ctx, existingWebhook)
-			if err != nil {
-				setupLog.Error(err, "Error updating validatingwebhookconfiguration")
-				time.Sleep(webhookRetryDelay)
-				continue
+/*
+applyWebhookConfiguration creates the webhook configuration if it does not exist. Otherwise it reads the existing
+configuration into existing, calls update to copy the webhooks of the desired configuration, and updates it.
+*/
+func applyWebhookConfiguration(ctx context.Context, k8sClient client.Client, desired, existing client.Object,
+	update func()) error {
+	gvk := desired.GetObjectKind().GroupVersionKind()
+	existing.GetObjectKind().SetGroupVersionKind(gvk)
+
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: desired.GetName()}, existing)
+	if err != nil && errors.IsNotFound(err) {
+		// Webhook not found. Create and return
+		setupLog.Info("Creating webhook configuration", "Kind", gvk.Kind, "Name", desired.GetName())
+		return k8sClient.Create(ctx, desired)
+	} else if err != nil {
+		return err
+	}
+
+	// Webhook already exists. Update and return
+	setupLog.Info("Updating existing webhook configuration", "Kind", gvk.Kind, "Name", desired.GetName())
+	update()
+	return k8sClient.Update(ctx, existing)
+}
+
+/*
+applyConversionWebhooks configures the CustomResourceDefinitions served in several versions to be converted by the
+conversion webhook of the operator. When caBundle is set, it is injected into the conversion webhook. Otherwise the CA
+bundle injected into an existing webhook configuration is kept, and the CRDs are annotated so that the service CA
+injects it.
+*/
+func applyConversionWebhooks(ctx context.Context, k8sClient client.Client, namespace string, caBundle []byte) error {
+	for _, name := range discoveryv1.ConvertedCRDNames {
+		crd := &apixv1.CustomResourceDefinition{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			return err
+		}
+
+		existing := crd.DeepCopy()
+		conversion := discoveryv1.CustomResourceConversion(namespace)
+		crd.Spec.Conversion = conversion
+
+		if caBundle != nil {
+			conversion.Webhook.ClientConfig.CABundle = caBundle
+			delete(crd.Annotations, injectCABundleAnnotation)
+		} else {
+			if c := existing.Spec.Conversion; c != nil && c.Webhook != nil && c.Webhook.ClientConfig != nil {
+				conversion.Webhook.ClientConfig.CABundle = c.Webhook.ClientConfig.CABundle
+			}
+			if crd.Annotations == nil {
+				crd.Annotations = map[string]string{}
 			}
-			return nil
+			crd.Annotations[injectCABundleAnnotation] = "true"
+		}
+
+		if equality.Semantic.DeepEqual(existing, crd) {
+			continue
+		}
+
+		setupLog.Info("Updating conversion webhook of CustomResourceDefinition", "Name", name)
+		if err := k8sClient.Update(ctx, crd); err != nil {
+			return err
 		}
 	}
-	return fmt.Errorf("unable to ensure validatingwebhook exists in allotted time")
+	return nil
 }
 
 func addDiscoverySecretWatch(ctx context.Context, mgr ctrl.Manager, uncachedClient client.Client) {
@@ -383,7 +611,7 @@ func handleSecretEvent(ctx context.Context, secret *corev1.Secret, uncachedClien
 	}
 
 	for _, discoveryConfig := range allDiscoveryConfigs.Items {
-		if discoveryConfig.GetName() == controllers.DefaultDiscoveryConfigName {
+		if discoveryConfig.GetName() == discoveryv1.DefaultDiscoveryConfigName {
 			// NamespacedName for the Credential from DiscoveryConfig
 			credential := types.NamespacedName{
 				Name:      discoveryConfig.Spec.Credential,
@@ -0,0 +1,395 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// CACertKey is the key of the PEM encoded CA certificate in the Secret.
+	CACertKey = "ca.crt"
+
+	// CAKeyKey is the key of the PEM encoded CA private key in the Secret.
+	CAKeyKey = "ca.key"
+
+	// CABundleKey is the key of the CA bundle in the Secret. It contains the current CA certificate and the previous
+	// ones that are still valid, so that serving certificates signed by either are trusted during a CA rotation.
+	CABundleKey = "ca-bundle.crt"
+
+	// DefaultCAValidity is how long a generated CA certificate is valid.
+	DefaultCAValidity = 2 * 365 * 24 * time.Hour
+
+	// DefaultCertValidity is how long a generated serving certificate is valid.
+	DefaultCertValidity = 90 * 24 * time.Hour
+
+	// DefaultCheckInterval is how often the certificates are checked for rotation.
+	DefaultCheckInterval = time.Hour
+
+	// DefaultInjectionDelay is how long the previous serving certificate is kept after a new CA bundle is injected,
+	// so that the API server has loaded it before a serving certificate signed by the new CA is presented.
+	DefaultInjectionDelay = time.Minute
+
+	// clockSkew is how far in the past the certificates become valid, to tolerate clock differences between nodes.
+	clockSkew = time.Hour
+)
+
+var logger = logf.Log.WithName("certificate-manager")
+
+/*
+Manager generates a self-signed CA and a serving certificate for the webhook service into a Secret, and rotates them
+before they expire. The serving certificate is served from memory through GetCertificate, so that rotations are picked
+up by the webhook server without a restart. OnRotate is called with the CA bundle whenever it changes, so that it can be
+injected into the webhook configurations.
+*/
+type Manager struct {
+	Client         client.Client
+	SecretName     string
+	Namespace      string
+	DNSNames       []string
+	CAValidity     time.Duration
+	CertValidity   time.Duration
+	CheckInterval  time.Duration
+	InjectionDelay time.Duration
+	OnRotate       func(ctx context.Context, caBundle []byte) error
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	caBundle []byte
+	now      func() time.Time
+}
+
+// certificates are the serving certificate and the CA bundle loaded from the Secret.
+type certificates struct {
+	cert     *tls.Certificate
+	caBundle []byte
+}
+
+// NewManager returns a Manager for the certificates of the service in the namespace, stored in the named Secret.
+func NewManager(c client.Client, namespace, secretName, serviceName string) *Manager {
+	return &Manager{
+		Client:     c,
+		SecretName: secretName,
+		Namespace:  namespace,
+		DNSNames: []string{
+			fmt.Sprintf("%s.%s.svc", serviceName, namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", serviceName, namespace),
+		},
+		CAValidity:     DefaultCAValidity,
+		CertValidity:   DefaultCertValidity,
+		CheckInterval:  DefaultCheckInterval,
+		InjectionDelay: DefaultInjectionDelay,
+		now:            time.Now,
+	}
+}
+
+// Ensure loads the certificates from the Secret, generating or rotating them first if needed.
+func (m *Manager) Ensure(ctx context.Context) error {
+	certs, err := m.reconcile(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.setCertificates(certs)
+	return nil
+}
+
+// CABundle returns the PEM encoded CA bundle that verifies the serving certificate.
+func (m *Manager) CABundle() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.caBundle
+}
+
+// GetCertificate returns the current serving certificate. It is meant to be set as the GetCertificate function of the
+// TLS config of the webhook server.
+func (m *Manager) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.cert == nil {
+		return nil, fmt.Errorf("no webhook serving certificate has been loaded")
+	}
+	return m.cert, nil
+}
+
+/*
+Start checks the certificates every CheckInterval until the context is done. A new CA bundle is passed to OnRotate, and
+the previous serving certificate is kept for InjectionDelay afterwards, so that the API server trusts the new serving
+certificate by the time it is used. When OnRotate fails, the previous serving certificate is kept and OnRotate is
+retried at the next check.
+*/
+func (m *Manager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.CheckInterval)
+	defer ticker.Stop()
+
+	var injected []byte
+	for {
+		certs, err := m.reconcile(ctx)
+		if err != nil {
+			logger.Error(err, "Failed to check webhook certificates", "Secret", m.SecretName)
+		} else {
+			// A serving certificate signed by a CA of the bundle that is already served or injected is trusted.
+			trusted := m.OnRotate == nil || len(m.CABundle()) == 0 || bytes.Equal(certs.caBundle, m.CABundle()) ||
+				bytes.Equal(certs.caBundle, injected)
+			if m.OnRotate != nil && !bytes.Equal(certs.caBundle, injected) {
+				if err := m.OnRotate(ctx, certs.caBundle); err != nil {
+					logger.Error(err, "Failed to inject the webhook CA bundle")
+				} else {
+					injected = certs.caBundle
+					if !trusted {
+						logger.Info("Waiting for the new webhook CA bundle to be loaded", "Delay", m.InjectionDelay)
+						select {
+						case <-ctx.Done():
+							return nil
+						case <-time.After(m.InjectionDelay):
+						}
+						trusted = true
+					}
+				}
+			}
+
+			if trusted {
+				m.setCertificates(certs)
+			} else {
+				logger.Info("Keeping the previous webhook serving certificate until the new CA bundle is injected")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, since every replica serves the webhooks.
+func (m *Manager) NeedLeaderElection() bool {
+	return false
+}
+
+func (m *Manager) setCertificates(certs *certificates) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cert, m.caBundle = certs.cert, certs.caBundle
+}
+
+/*
+reconcile reads the Secret and regenerates the CA when it is missing, invalid or close to expiry, and the serving
+certificate when it is missing, invalid, not signed by the CA, issued for other DNS names or close to expiry. The Secret
+is only written when something changed. A conflict with another replica writing the Secret is returned as an error, and
+the certificates it wrote are loaded at the next check.
+*/
+func (m *Manager) reconcile(ctx context.Context) (*certificates, error) {
+	secret := &corev1.Secret{}
+	nn := types.NamespacedName{Name: m.SecretName, Namespace: m.Namespace}
+	found := true
+	if err := m.Client.Get(ctx, nn, secret); apierrors.IsNotFound(err) {
+		found = false
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: m.SecretName, Namespace: m.Namespace},
+			Type:       corev1.SecretTypeTLS,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", m.Namespace, m.SecretName, err)
+	}
+
+	data := map[string][]byte{}
+	for k, v := range secret.Data {
+		data[k] = v
+	}
+
+	now := m.now()
+	changed := false
+
+	ca, caKey, err := parseKeyPair(data[CACertKey], data[CAKeyKey])
+	if err != nil || !ca.IsCA || needsRenewal(ca, now) {
+		logger.Info("Generating webhook CA certificate", "Secret", m.SecretName)
+		if ca, caKey, err = m.generateCA(now); err != nil {
+			return nil, err
+		}
+		data[CACertKey], data[CAKeyKey] = encodeCertificate(ca), encodeKey(caKey)
+		changed = true
+	}
+
+	if !m.isValidServingCert(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey], ca, now) {
+		logger.Info("Generating webhook serving certificate", "Secret", m.SecretName, "DNSNames", m.DNSNames)
+		cert, key, err := m.generateServingCert(now, ca, caKey)
+		if err != nil {
+			return nil, err
+		}
+		data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey] = encodeCertificate(cert), encodeKey(key)
+		changed = true
+	}
+
+	if bundle := buildCABundle(ca, data[CABundleKey], now); !bytes.Equal(bundle, data[CABundleKey]) {
+		data[CABundleKey] = bundle
+		changed = true
+	}
+
+	if changed {
+		secret.Data = data
+		if !found {
+			err = m.Client.Create(ctx, secret)
+		} else {
+			err = m.Client.Update(ctx, secret)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write Secret %s/%s: %w", m.Namespace, m.SecretName, err)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the webhook serving certificate: %w", err)
+	}
+	return &certificates{cert: &cert, caBundle: data[CABundleKey]}, nil
+}
+
+// isValidServingCert returns true if the serving certificate can be kept.
+func (m *Manager) isValidServingCert(certPEM, keyPEM []byte, ca *x509.Certificate, now time.Time) bool {
+	cert, _, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil || needsRenewal(cert, now) || cert.CheckSignatureFrom(ca) != nil {
+		return false
+	}
+
+	dnsNames := slices.Clone(cert.DNSNames)
+	expected := slices.Clone(m.DNSNames)
+	slices.Sort(dnsNames)
+	slices.Sort(expected)
+	return slices.Equal(dnsNames, expected)
+}
+
+func (m *Manager) generateCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca@%d", m.SecretName, now.Unix())},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(m.CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return createCertificate(template, nil, nil)
+}
+
+func (m *Manager) generateServingCert(now time.Time, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (
+	*x509.Certificate, *ecdsa.PrivateKey, error) {
+	notAfter := now.Add(m.CertValidity)
+	if notAfter.After(ca.NotAfter) {
+		notAfter = ca.NotAfter
+	}
+
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: m.DNSNames[0]},
+		DNSNames:    m.DNSNames,
+		NotBefore:   now.Add(-clockSkew),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return createCertificate(template, ca, caKey)
+}
+
+// createCertificate generates a key and a certificate from the template, signed by the parent or self-signed if the
+// parent is nil.
+func createCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (
+	*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, key, nil
+}
+
+// parseKeyPair parses the PEM encoded certificate and ECDSA key, and checks that they match.
+func parseKeyPair(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", pair.PrivateKey)
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// needsRenewal returns true if less than a fifth of the lifetime of the certificate remains.
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotAfter.Sub(now) < lifetime/5
+}
+
+// buildCABundle returns the CA certificate followed by the certificates of the previous bundle that are still valid.
+func buildCABundle(ca *x509.Certificate, previous []byte, now time.Time) []byte {
+	bundle := encodeCertificate(ca)
+	for rest := previous; len(rest) > 0; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || cert.Equal(ca) || now.After(cert.NotAfter) {
+			continue
+		}
+		bundle = append(bundle, encodeCertificate(cert)...)
+	}
+	return bundle
+}
+
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func encodeKey(key *ecdsa.PrivateKey) []byte {
+	// Marshalling an ECDSA key on a named curve cannot fail.
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
@@ -0,0 +1,240 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "discovery"
+	testSecret    = "webhook-service"
+)
+
+func newTestManager(c client.Client, now *time.Time) *Manager {
+	m := NewManager(c, testNamespace, testSecret, "webhook-service")
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+// verifyServingCert checks that the serving certificate of the manager is trusted by its CA bundle at the time.
+func verifyServingCert(t *testing.T, m *Manager, now time.Time) *x509.Certificate {
+	t.Helper()
+
+	cert, err := m.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse serving certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(m.CABundle()) {
+		t.Fatalf("CABundle() contains no certificates")
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		DNSName:     "webhook-service.discovery.svc",
+		Roots:       roots,
+		CurrentTime: now,
+	})
+	if err != nil {
+		t.Fatalf("serving certificate is not trusted by the CA bundle: %v", err)
+	}
+	return leaf
+}
+
+func getSecret(t *testing.T, c client.Client) *corev1.Secret {
+	t.Helper()
+
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: testSecret, Namespace: testNamespace}, secret); err != nil {
+		t.Fatalf("failed to get Secret: %v", err)
+	}
+	return secret
+}
+
+func TestManager_Ensure(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := fake.NewClientBuilder().Build()
+	m := newTestManager(c, &now)
+
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	first := verifyServingCert(t, m, now)
+	secret := getSecret(t, c)
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("Secret type = %s, want %s", secret.Type, corev1.SecretTypeTLS)
+	}
+
+	// A second manager, like another replica, reuses the certificates of the Secret.
+	other := newTestManager(c, &now)
+	if err := other.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if got := verifyServingCert(t, other, now); !got.Equal(first) {
+		t.Errorf("Ensure() regenerated a valid serving certificate")
+	}
+	if got := getSecret(t, c); got.ResourceVersion != secret.ResourceVersion {
+		t.Errorf("Ensure() updated the Secret although its certificates are valid")
+	}
+}
+
+func TestManager_Ensure_invalidSecret(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testSecret, Namespace: testNamespace},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte(""),
+			corev1.TLSPrivateKeyKey: []byte(""),
+		},
+	}).Build()
+	m := newTestManager(c, &now)
+
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	verifyServingCert(t, m, now)
+	if secret := getSecret(t, c); len(secret.Data[corev1.TLSCertKey]) == 0 {
+		t.Errorf("Ensure() did not replace the empty serving certificate in the Secret")
+	}
+}
+
+func TestManager_Ensure_rotation(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	c := fake.NewClientBuilder().Build()
+	m := newTestManager(c, &now)
+
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	first := verifyServingCert(t, m, now)
+	caBundle := m.CABundle()
+
+	// The serving certificate is renewed when less than a fifth of its lifetime remains, with the same CA.
+	now = start.Add(DefaultCertValidity * 9 / 10)
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	second := verifyServingCert(t, m, now)
+	if second.Equal(first) {
+		t.Errorf("Ensure() did not renew the serving certificate close to expiry")
+	}
+	if !bytes.Equal(m.CABundle(), caBundle) {
+		t.Errorf("Ensure() changed the CA bundle when renewing the serving certificate")
+	}
+
+	// The CA is renewed when less than a fifth of its lifetime remains, and the previous CA stays in the bundle.
+	now = start.Add(DefaultCAValidity * 9 / 10)
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	verifyServingCert(t, m, now)
+	if !bytes.HasSuffix(m.CABundle(), caBundle) || bytes.Equal(m.CABundle(), caBundle) {
+		t.Errorf("CABundle() = %s, want the new CA followed by the previous one", m.CABundle())
+	}
+
+	// The previous CA is removed from the bundle once it expires.
+	now = start.Add(DefaultCAValidity + 24*time.Hour)
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	verifyServingCert(t, m, now)
+	if bytes.Contains(m.CABundle(), caBundle) {
+		t.Errorf("CABundle() still contains the expired CA")
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := fake.NewClientBuilder().Build()
+	m := newTestManager(c, &now)
+	m.CheckInterval = time.Millisecond
+
+	injected := make(chan []byte, 1)
+	m.OnRotate = func(_ context.Context, caBundle []byte) error {
+		injected <- caBundle
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Start(ctx) }()
+
+	select {
+	case caBundle := <-injected:
+		if len(caBundle) == 0 {
+			t.Errorf("OnRotate() called with an empty CA bundle")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("OnRotate() was not called")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+	verifyServingCert(t, m, now)
+}
+
+func TestManager_Start_injectionFailure(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	c := fake.NewClientBuilder().Build()
+	m := newTestManager(c, &now)
+	m.CheckInterval = time.Millisecond
+	m.InjectionDelay = time.Millisecond
+	// The serving certificate stays valid until the CA is renewed, so that the previous one can still be verified.
+	m.CertValidity = DefaultCAValidity
+
+	if err := m.Ensure(context.TODO()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	first := verifyServingCert(t, m, now)
+
+	// The CA is renewed by the first check, and OnRotate returns the errors sent by the test.
+	now = start.Add(DefaultCAValidity * 9 / 10)
+	results := make(chan error)
+	m.OnRotate = func(_ context.Context, _ []byte) error {
+		return <-results
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Start(ctx) }()
+
+	// The second error is only received once the check that failed to inject the CA bundle has completed.
+	results <- errors.New("injection failed")
+	results <- errors.New("injection failed")
+	if got := verifyServingCert(t, m, now); !got.Equal(first) {
+		t.Errorf("Start() served a certificate signed by a CA that was not injected")
+	}
+
+	results <- nil
+	deadline := time.Now().Add(10 * time.Second)
+	for verifyServingCert(t, m, now).Equal(first) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Start() did not serve the new certificate after the CA bundle was injected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+ParseSecretForAuth parses the given Secret to retrieve authentication credentials.
+Depending on the "auth_method" field in the secret, it returns either service account credentials
+(client_id, client_secret) or an offline token (ocmAPIToken). If "auth_method" is not set, it
+defaults to using the "offline-token" method. Returns an error if the expected fields are missing.
+*/
+func ParseSecretForAuth(secret *corev1.Secret) (AuthRequest, error) {
+	// Set the default auth_method to "offline-token"
+	authMethod := "offline-token"
+
+	// Check if the "auth_method" key is present in the Secret data
+	if method, found := secret.Data["auth_method"]; found {
+		authMethod = string(method)
+	}
+
+	credentials := AuthRequest{
+		AuthMethod: strings.TrimSuffix(string(authMethod), "\n"), // Set the authentication method
+	}
+
+	// Handle based on the "auth_method" value
+	switch credentials.AuthMethod {
+	case "service-account":
+		// Retrieve client_id and client_secret for service-account auth method
+		clientID, idOk := secret.Data["client_id"]
+		clientSecret, secretOk := secret.Data["client_secret"]
+
+		if !idOk || !secretOk {
+			return credentials, fmt.Errorf(
+				"%s: bad format: secret must contain client_id and client_secret", secret.Name)
+		}
+
+		credentials.ID = strings.TrimSuffix(string(clientID), "\n")
+		credentials.Secret = strings.TrimSuffix(string(clientSecret), "\n")
+
+	case "offline-token":
+		// Retrive ocmAPIToken for offline-token auth method
+		token, tokenOk := secret.Data["ocmAPIToken"]
+		if !tokenOk {
+			return credentials, fmt.Errorf("%s: bad format: secret must contain ocmAPIToken", secret.Name)
+		}
+
+		credentials.Token = strings.TrimSuffix(string(token), "\n")
+
+	default:
+		return credentials, fmt.Errorf("%s: bad format: unsupported auth_method:  %s", secret.Name, authMethod)
+	}
+
+	return credentials, nil
+}
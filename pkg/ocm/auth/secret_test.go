@@ -0,0 +1,117 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package auth
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ParseSecretForAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  *corev1.Secret
+		want    AuthRequest
+		wantErr bool
+	}{
+		{
+			name: "Dummy token set",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+				Data: map[string][]byte{
+					"auth_method": []byte("offline-token"),
+					"ocmAPIToken": []byte("dummytoken"),
+				},
+			},
+			want: AuthRequest{
+				AuthMethod: "offline-token",
+				Token:      "dummytoken",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Missing token",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+			},
+			want: AuthRequest{
+				AuthMethod: "offline-token",
+				Token:      "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Dummy service account token",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+				Data: map[string][]byte{
+					"auth_method":   []byte("service-account"),
+					"client_id":     []byte("dc05925d-630b-408b-bfb7-02099be7b789"),
+					"client_secret": []byte("ZZocNUZWgYSuJHIqK0j0D1mZVdufng6z"), // notsecret
+				},
+			},
+			want: AuthRequest{
+				AuthMethod: "service-account",
+				ID:         "dc05925d-630b-408b-bfb7-02099be7b789",
+				Secret:     "ZZocNUZWgYSuJHIqK0j0D1mZVdufng6z", // notsecret
+			},
+			wantErr: false,
+		},
+		{
+			name: "Missing field service account",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+				Data: map[string][]byte{
+					"auth_method": []byte("service-account"),
+					"client_id":   []byte("dc05925d-630b-408b-bfb7-02099be7b789"),
+				},
+			},
+			want: AuthRequest{
+				AuthMethod: "service-account",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid authentication method",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+				Data: map[string][]byte{
+					"auth_method": []byte("invalid-method"),
+				},
+			},
+			want: AuthRequest{
+				AuthMethod: "invalid-method",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSecretForAuth(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSecretForAuth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseSecretForAuth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
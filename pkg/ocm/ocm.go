@@ -67,7 +67,7 @@ func DiscoverClusters(authRequest auth.AuthRequest, filters discovery.Filter) ([
 	var discoveredClusters []discovery.DiscoveredCluster
 	for _, sub := range subscriptions {
 		// Build a DiscoveredCluster object from the subscription information
-		if dc, valid := formatCluster(sub, clusterClient, log); valid {
+		if dc, valid := formatCluster(sub, clusterClient, log, filters.IncludeUnreported); valid {
 			discoveredClusters = append(discoveredClusters, dc)
 		}
 	}
@@ -75,14 +75,21 @@ func DiscoverClusters(authRequest auth.AuthRequest, filters discovery.Filter) ([
 	return discoveredClusters, nil
 }
 
-// formatCluster converts a cluster from OCM form to DiscoveredCluster form, or returns false if it is not valid
-func formatCluster(sub subscription.Subscription, clusterClient cluster.Client, log logr.Logger) (discovery.DiscoveredCluster, bool) {
+// formatCluster converts a cluster from OCM form to DiscoveredCluster form, or returns false if it is not valid.
+// Clusters without telemetry metrics are only valid when includeUnreported is set, and have no OpenShift version.
+func formatCluster(sub subscription.Subscription, clusterClient cluster.Client, log logr.Logger,
+	includeUnreported bool) (discovery.DiscoveredCluster, bool) {
 	discoveredCluster := discovery.DiscoveredCluster{}
 	// TODO: consider refactoring to "filter" clusters ouside this function to retain function clarity
-	if len(sub.Metrics) == 0 {
+	if len(sub.Metrics) == 0 && !includeUnreported {
 		return discoveredCluster, false
 	}
 
+	openshiftVersion := ""
+	if len(sub.Metrics) > 0 {
+		openshiftVersion = sub.Metrics[0].OpenShiftVersion
+	}
+
 	// Determine API URL - use cluster_mgmt API for ROSA clusters, heuristic for others
 	apiURL := getAPIURL(sub, clusterClient, log)
 
@@ -103,12 +110,13 @@ func formatCluster(sub subscription.Subscription, clusterClient cluster.Client,
 			DisplayName:       computeDisplayName(sub),
 			Name:              sub.ExternalClusterID,
 			OCPClusterID:      sub.ExternalClusterID,
-			OpenshiftVersion:  sub.Metrics[0].OpenShiftVersion,
+			OpenshiftVersion:  openshiftVersion,
 			Provenance:        sub.Provenance,
 			Region:            sub.RegionID,
 			RHOCMClusterID:    sub.ClusterID,
 			Status:            sub.Status,
 			SupportLevel:      sub.SupportLevel,
+			TrialEndDate:      computeTrialEndDate(sub),
 			Type:              computeType(sub),
 			Usage:             sub.Usage,
 		},
@@ -232,6 +240,15 @@ func computeApiUrl(sub subscription.Subscription) string {
 	return ""
 }
 
+// computeTrialEndDate returns the end date of a trial subscription. OCM reports the zero time for subscriptions
+// that are not trials, so it is treated the same as a missing date.
+func computeTrialEndDate(sub subscription.Subscription) *metav1.Time {
+	if sub.TrialEndDate == nil || sub.TrialEndDate.IsZero() {
+		return nil
+	}
+	return sub.TrialEndDate
+}
+
 // computeType calculates the type of the cluster based on subscription.plan.id
 func computeType(sub subscription.Subscription) string {
 	switch sub.Plan.ID {
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	discovery "github.com/stolostron/discovery/api/v1"
 	"github.com/stolostron/discovery/pkg/ocm/auth"
 	"github.com/stolostron/discovery/pkg/ocm/cluster"
 	"github.com/stolostron/discovery/pkg/ocm/subscription"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -275,6 +278,39 @@ func Test_computeType(t *testing.T) {
 	}
 }
 
+func Test_computeTrialEndDate(t *testing.T) {
+	trialEnd := metav1.NewTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		sub  subscription.Subscription
+		want *metav1.Time
+	}{
+		{
+			name: "Trial subscription",
+			sub:  subscription.Subscription{TrialEndDate: &trialEnd},
+			want: &trialEnd,
+		},
+		{
+			name: "Zero trial end date",
+			sub:  subscription.Subscription{TrialEndDate: &metav1.Time{}},
+			want: nil,
+		},
+		{
+			name: "No trial end date",
+			sub:  subscription.Subscription{},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeTrialEndDate(tt.sub); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeTrialEndDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_IsUnauthorizedClient(t *testing.T) {
 	tests := []struct {
 		name string
@@ -522,3 +558,49 @@ type mockClusterClientImpl struct {
 func (m *mockClusterClientImpl) GetClusterByID(clusterID string) (*cluster.Cluster, error) {
 	return m.cluster, m.err
 }
+
+func Test_formatCluster(t *testing.T) {
+	log := logf.Log.WithName("test")
+
+	tests := []struct {
+		name              string
+		sub               subscription.Subscription
+		includeUnreported bool
+		wantValid         bool
+		wantVersion       string
+	}{
+		{
+			name: "Cluster with metrics",
+			sub: subscription.Subscription{
+				Status:  "Active",
+				Metrics: []subscription.Metrics{{OpenShiftVersion: "4.15.2"}},
+			},
+			wantValid:   true,
+			wantVersion: "4.15.2",
+		},
+		{
+			name:      "Cluster without metrics is dropped",
+			sub:       subscription.Subscription{Status: "Disconnected"},
+			wantValid: false,
+		},
+		{
+			name:              "Cluster without metrics is included",
+			sub:               subscription.Subscription{Status: "Reserved"},
+			includeUnreported: true,
+			wantValid:         true,
+			wantVersion:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, valid := formatCluster(tt.sub, nil, log, tt.includeUnreported)
+			if valid != tt.wantValid {
+				t.Fatalf("formatCluster() valid = %v, want %v", valid, tt.wantValid)
+			}
+			if valid && got.Spec.OpenshiftVersion != tt.wantVersion {
+				t.Errorf("formatCluster() version = %q, want %q", got.Spec.OpenshiftVersion, tt.wantVersion)
+			}
+		})
+	}
+}
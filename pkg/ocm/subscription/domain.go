@@ -55,6 +55,7 @@ type Subscription struct {
 	RegionID          string       `json:"region_id,omitempty" yaml:"region_id,omitempty"`
 	Status            string       `json:"status" yaml:"status"`
 	SupportLevel      string       `json:"support_level,omitempty" yaml:"support_level,omitempty"`
+	TrialEndDate      *metav1.Time `json:"trial_end_date,omitempty" yaml:"trial_end_date,omitempty"`
 	UpdatedAt         *metav1.Time `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
 	Usage             string       `json:"usage,omitempty" yaml:"usage,omitempty"`
 }
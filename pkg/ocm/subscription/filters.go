@@ -39,11 +39,11 @@ func all(s Subscription, fs []filterFunc) bool {
 func createFilters(f discovery.Filter) []filterFunc {
 	return []filterFunc{
 		statusFilter(),
-		clusterTypeFilter(f.ClusterTypes),
-		infrastructureProviderFilter(f.InfrastructureProviders),
+		clusterTypeFilter(f.ClusterTypes, f.IncludeUnreported),
+		infrastructureProviderFilter(f.InfrastructureProviders, f.IncludeUnreported),
 		openshiftVersionFilter(f.OpenShiftVersions),
-		regionFilter(f.Regions),
-		lastActiveFilter(time.Now(), f.LastActive),
+		regionFilter(f.Regions, f.IncludeUnreported),
+		lastActiveFilter(time.Now(), f.LastActive, f.IncludeUnreported),
 	}
 }
 
@@ -81,12 +81,13 @@ func openshiftVersionFilter(versions []discovery.Semver) filterFunc {
 	}
 }
 
-// lastActiveFilter filters out clusters that haven't been updated in the last n days
-func lastActiveFilter(currentDate time.Time, n int) filterFunc {
+// lastActiveFilter filters out clusters that haven't been updated in the last n days. Clusters that have never
+// reported telemetry are kept when includeUnreported is set.
+func lastActiveFilter(currentDate time.Time, n int, includeUnreported bool) filterFunc {
 	t := lastActiveDateTime(currentDate, n)
 	return func(sub Subscription) bool {
 		if sub.LastTelemetryDate == nil {
-			return false
+			return includeUnreported
 		}
 		return sub.LastTelemetryDate.Time.After(t)
 	}
@@ -100,14 +101,16 @@ func lastActiveDateTime(currentDate time.Time, daysAgo int) time.Time {
 	return currentDate.AddDate(0, 0, -daysAgo)
 }
 
-func commonFilter[T comparable](list []T, matchFunc func(sub Subscription) T) filterFunc {
+// commonFilter filters out subscriptions whose matched value is not in the given list. Subscriptions without
+// telemetry metrics are filtered out unless includeUnreported is set.
+func commonFilter[T comparable](list []T, includeUnreported bool, matchFunc func(sub Subscription) T) filterFunc {
 	if len(list) == 0 {
 		// noop filter
 		return func(sub Subscription) bool { return true }
 	}
 
 	return func(sub Subscription) bool {
-		if len(sub.Metrics) == 0 {
+		if len(sub.Metrics) == 0 && !includeUnreported {
 			return false
 		}
 
@@ -122,22 +125,22 @@ func commonFilter[T comparable](list []T, matchFunc func(sub Subscription) T) fi
 }
 
 // clusterTypeFilter filters out subscriptions with cluster types not in the given list
-func clusterTypeFilter(clusterTypes []string) filterFunc {
-	return commonFilter(clusterTypes, func(sub Subscription) string {
+func clusterTypeFilter(clusterTypes []string, includeUnreported bool) filterFunc {
+	return commonFilter(clusterTypes, includeUnreported, func(sub Subscription) string {
 		return sub.Plan.ID
 	})
 }
 
 // infrastructureProviderFilter filters out subscriptions with cloud providers not in the given list
-func infrastructureProviderFilter(infrastructures []string) filterFunc {
-	return commonFilter(infrastructures, func(sub Subscription) string {
+func infrastructureProviderFilter(infrastructures []string, includeUnreported bool) filterFunc {
+	return commonFilter(infrastructures, includeUnreported, func(sub Subscription) string {
 		return sub.CloudProviderID
 	})
 }
 
 // regionFilter filters out subscriptions with regions not in the given list
-func regionFilter(regions []string) filterFunc {
-	return commonFilter(regions, func(sub Subscription) string {
+func regionFilter(regions []string, includeUnreported bool) filterFunc {
+	return commonFilter(regions, includeUnreported, func(sub Subscription) string {
 		return sub.RegionID
 	})
 }
@@ -59,6 +59,41 @@ func TestFilter(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unreported clusters are kept when includeUnreported is set",
+			f:    discovery.Filter{LastActive: 7, Regions: []string{"us-east-1"}, IncludeUnreported: true},
+			subs: []Subscription{
+				{
+					DisplayName: "disconnected-subscription",
+					Status:      "Disconnected",
+					RegionID:    "us-east-1",
+				},
+				{
+					DisplayName: "filtered-by-region",
+					Status:      "Disconnected",
+					RegionID:    "eu-west-1",
+				},
+			},
+			want: []Subscription{
+				{
+					DisplayName: "disconnected-subscription",
+					Status:      "Disconnected",
+					RegionID:    "us-east-1",
+				},
+			},
+		},
+		{
+			name: "unreported clusters are filtered out by default",
+			f:    discovery.Filter{LastActive: 7, Regions: []string{"us-east-1"}},
+			subs: []Subscription{
+				{
+					DisplayName: "disconnected-subscription",
+					Status:      "Disconnected",
+					RegionID:    "us-east-1",
+				},
+			},
+			want: []Subscription{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -227,7 +262,7 @@ func Test_lastActiveFilter(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filter := lastActiveFilter(tt.current, tt.daysAgo)
+			filter := lastActiveFilter(tt.current, tt.daysAgo, false)
 			if got := filter(tt.sub); got != tt.want {
 				t.Errorf("lastActiveFilter() = %v, want %v", got, tt.want)
 			}
@@ -92,7 +92,12 @@ func prepareRequest(request SubscriptionRequest) (*http.Request, error) {
 		display_name is not null OR external_cluster_id is not null OR cluster_id is not null)
 	*/
 	query.Add("orderBy", "created_at desc")
-	query.Add("search", "status NOT IN ('Deprovisioned', 'Archived', 'Reserved')")
+	if request.Filter.IncludeUnreported {
+		// Reserved subscriptions belong to clusters that are still being installed.
+		query.Add("search", "status NOT IN ('Deprovisioned', 'Archived')")
+	} else {
+		query.Add("search", "status NOT IN ('Deprovisioned', 'Archived', 'Reserved')")
+	}
 	query.Add("search", "external_cluster_id is not null")
 
 	applyPreFilters(query, request.Filter)
@@ -8,6 +8,7 @@ import (
 	"os"
 	"testing"
 
+	discovery "github.com/stolostron/discovery/api/v1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,3 +42,28 @@ func TestProviderGetSubscriptionsNoError(t *testing.T) {
 	assert.Nil(t, err)
 	assert.EqualValues(t, 1, len(response.Items))
 }
+
+func Test_prepareRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter discovery.Filter
+		want   string
+	}{
+		{
+			name: "Reserved subscriptions are excluded by default",
+			want: "status NOT IN ('Deprovisioned', 'Archived', 'Reserved')",
+		},
+		{
+			name:   "Reserved subscriptions are included with unreported clusters",
+			filter: discovery.Filter{IncludeUnreported: true},
+			want:   "status NOT IN ('Deprovisioned', 'Archived')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := prepareRequest(SubscriptionRequest{Filter: tt.filter})
+			assert.Nil(t, err)
+			assert.Contains(t, request.URL.Query()["search"], tt.want)
+		})
+	}
+}
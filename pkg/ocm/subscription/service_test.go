@@ -70,6 +70,40 @@ func TestGetSubscriptionsNoError(t *testing.T) {
 	assert.NotNil(t, response)
 }
 
+func TestGetSubscriptionsIncludeUnreported(t *testing.T) {
+	getSubscriptionsFunc = func(request SubscriptionRequest) (*SubscriptionResponse, *SubscriptionError) {
+		return &SubscriptionResponse{
+			Kind:  "SubscriptionList",
+			Page:  1,
+			Size:  1,
+			Total: 1,
+			Items: []Subscription{
+				{
+					Kind:   "Subscription",
+					ID:     "123abc",
+					Href:   "/api/accounts_mgmt/v1/subscriptions/123abc",
+					Status: "Disconnected",
+				},
+			},
+		}, nil
+	}
+	SubscriptionProvider = &subscriptionProviderMock{} //without this line, the real api is fired
+
+	response, err := NewClient(SubscriptionRequest{
+		Token:  "access_token",
+		Filter: discovery.Filter{LastActive: 7},
+	}).GetSubscriptions()
+	assert.Nil(t, err)
+	assert.Empty(t, response)
+
+	response, err = NewClient(SubscriptionRequest{
+		Token:  "access_token",
+		Filter: discovery.Filter{LastActive: 7, IncludeUnreported: true},
+	}).GetSubscriptions()
+	assert.Nil(t, err)
+	assert.Len(t, response, 1)
+}
+
 func TestNewClient(t *testing.T) {
 	subscriptionRequestConfig := SubscriptionRequest{
 		Token:   "test",
@@ -58,6 +58,39 @@ func GetAPIServerTLSProfile(ctx context.Context, cl client.Client) (*configv1.TL
 	return configv1.TLSProfiles[configv1.TLSProfileIntermediateType], nil
 }
 
+/*
+GetFlagTLSProfile returns the TLS profile configured by flags, used when the cluster has no OpenShift APIServer
+resource. The profile type is Old, Intermediate, Modern or Custom. The minimum TLS version and the cipher suites, in
+OpenSSL format, override those of the profile when set, and are required for the Custom profile.
+*/
+func GetFlagTLSProfile(profileType, minTLSVersion string, ciphers []string) (*configv1.TLSProfileSpec, error) {
+	profile := &configv1.TLSProfileSpec{}
+	if profileType != string(configv1.TLSProfileCustomType) {
+		base, ok := configv1.TLSProfiles[configv1.TLSProfileType(profileType)]
+		if !ok {
+			return nil, fmt.Errorf("invalid TLS profile %q, it must be Old, Intermediate, Modern or Custom", profileType)
+		}
+		profile = base.DeepCopy()
+	} else if minTLSVersion == "" || len(ciphers) == 0 {
+		return nil, fmt.Errorf("the Custom TLS profile requires a minimum TLS version and cipher suites")
+	}
+
+	if minTLSVersion != "" {
+		switch version := configv1.TLSProtocolVersion(minTLSVersion); version {
+		case configv1.VersionTLS10, configv1.VersionTLS11, configv1.VersionTLS12, configv1.VersionTLS13:
+			profile.MinTLSVersion = version
+		default:
+			return nil, fmt.Errorf("invalid minimum TLS version %q, it must be VersionTLS10, VersionTLS11, "+
+				"VersionTLS12 or VersionTLS13", minTLSVersion)
+		}
+	}
+
+	if len(ciphers) > 0 {
+		profile.Ciphers = ciphers
+	}
+	return profile, nil
+}
+
 // ConvertTLSVersion converts OpenShift TLSProtocolVersion string to crypto/tls uint16 constant.
 // Returns tls.VersionTLS12 as default if the version string is not recognized.
 func ConvertTLSVersion(version configv1.TLSProtocolVersion) uint16 {
@@ -0,0 +1,79 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package ocm
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func Test_GetFlagTLSProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		profileType   string
+		minTLSVersion string
+		ciphers       []string
+		want          *configv1.TLSProfileSpec
+		wantErr       bool
+	}{
+		{
+			name:        "Predefined profile",
+			profileType: "Modern",
+			want:        configv1.TLSProfiles[configv1.TLSProfileModernType],
+		},
+		{
+			name:          "Predefined profile with overrides",
+			profileType:   "Intermediate",
+			minTLSVersion: "VersionTLS13",
+			ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256"},
+			want: &configv1.TLSProfileSpec{
+				MinTLSVersion: configv1.VersionTLS13,
+				Ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256"},
+			},
+		},
+		{
+			name:          "Custom profile",
+			profileType:   "Custom",
+			minTLSVersion: "VersionTLS12",
+			ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384"},
+			want: &configv1.TLSProfileSpec{
+				MinTLSVersion: configv1.VersionTLS12,
+				Ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384"},
+			},
+		},
+		{
+			name:        "Custom profile without cipher suites",
+			profileType: "Custom",
+			wantErr:     true,
+		},
+		{
+			name:        "Unknown profile",
+			profileType: "Strict",
+			wantErr:     true,
+		},
+		{
+			name:          "Unknown minimum TLS version",
+			profileType:   "Intermediate",
+			minTLSVersion: "TLS1.2",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFlagTLSProfile(tt.profileType, tt.minTLSVersion, tt.ciphers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetFlagTLSProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetFlagTLSProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Overrides must not change the predefined profiles.
+	if configv1.TLSProfiles[configv1.TLSProfileIntermediateType].MinTLSVersion != configv1.VersionTLS12 {
+		t.Errorf("GetFlagTLSProfile() changed the predefined Intermediate profile")
+	}
+}
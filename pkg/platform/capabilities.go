@@ -0,0 +1,91 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package platform
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var logger = logf.Log.WithName("platform")
+
+// The optional APIs that the operator uses when the hub serves them.
+var (
+	// APIServer is the OpenShift resource that holds the TLS security profile of the cluster.
+	APIServer = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "APIServer"}
+
+	// ManagedCluster is the OCM resource that discovered clusters are imported as.
+	ManagedCluster = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1",
+		Kind: "ManagedCluster"}
+
+	// KlusterletAddonConfig is the ACM resource that configures the addons of an imported cluster.
+	KlusterletAddonConfig = schema.GroupVersionKind{Group: "agent.open-cluster-management.io", Version: "v1",
+		Kind: "KlusterletAddonConfig"}
+
+	// ClusterManagementAddOn is the OCM resource that the hosted addons of MultiClusterEngineHCP clusters are
+	// placed with.
+	ClusterManagementAddOn = schema.GroupVersionKind{Group: "addon.open-cluster-management.io", Version: "v1alpha1",
+		Kind: "ClusterManagementAddOn"}
+
+	// OptionalAPIs are the APIs detected at startup.
+	OptionalAPIs = []schema.GroupVersionKind{APIServer, ManagedCluster, KlusterletAddonConfig, ClusterManagementAddOn}
+)
+
+/*
+Capabilities records which optional APIs the hub serves, so that the operator can run on clusters without the OpenShift
+config APIs or the OCM CRDs. A nil Capabilities assumes that every API is served.
+*/
+type Capabilities struct {
+	available map[schema.GroupVersionKind]bool
+}
+
+// NewCapabilities returns Capabilities where only the given kinds are available.
+func NewCapabilities(gvks ...schema.GroupVersionKind) *Capabilities {
+	c := &Capabilities{available: map[schema.GroupVersionKind]bool{}}
+	for _, gvk := range gvks {
+		c.available[gvk] = true
+	}
+	return c
+}
+
+// Detect queries the discovery API for the given kinds. A group version that is not served means that its kinds are
+// unavailable, while any other discovery error is returned.
+func Detect(dc discovery.DiscoveryInterface, gvks ...schema.GroupVersionKind) (*Capabilities, error) {
+	c := NewCapabilities()
+	served := map[schema.GroupVersion]map[string]bool{}
+
+	for _, gvk := range gvks {
+		gv := gvk.GroupVersion()
+		if _, found := served[gv]; !found {
+			served[gv] = map[string]bool{}
+
+			resources, err := dc.ServerResourcesForGroupVersion(gv.String())
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to discover the resources of %s: %w", gv, err)
+			}
+			if resources != nil {
+				for _, r := range resources.APIResources {
+					served[gv][r.Kind] = true
+				}
+			}
+		}
+
+		c.available[gvk] = served[gv][gvk.Kind]
+		if !c.available[gvk] {
+			logger.Info("API is not served by the cluster, features that use it are disabled", "API", gvk.String())
+		}
+	}
+	return c, nil
+}
+
+// Has returns true if the kind is served by the hub, or if the capabilities were not detected.
+func (c *Capabilities) Has(gvk schema.GroupVersionKind) bool {
+	if c == nil {
+		return true
+	}
+	return c.available[gvk]
+}
@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package platform
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func Test_Detect(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	dc.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "cluster.open-cluster-management.io/v1",
+			APIResources: []metav1.APIResource{{Name: "managedclusters", Kind: "ManagedCluster"}},
+		},
+		{
+			GroupVersion: "addon.open-cluster-management.io/v1alpha1",
+			APIResources: []metav1.APIResource{{Name: "managedclusteraddons", Kind: "ManagedClusterAddOn"}},
+		},
+	}
+
+	c, err := Detect(dc, OptionalAPIs...)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: APIServer.Kind, want: false},
+		{name: ManagedCluster.Kind, want: true},
+		{name: KlusterletAddonConfig.Kind, want: false},
+		{name: ClusterManagementAddOn.Kind, want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Has(OptionalAPIs[i]); got != tt.want {
+				t.Errorf("Has(%s) = %v, want %v", OptionalAPIs[i], got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Detect_error(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	dc.AddReactor("get", "resource", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+
+	if _, err := Detect(dc, ManagedCluster); err == nil {
+		t.Errorf("Detect() error = nil, want the discovery error")
+	}
+}
+
+func Test_Capabilities_Has_nil(t *testing.T) {
+	var c *Capabilities
+	if !c.Has(ManagedCluster) {
+		t.Errorf("Has() = false, want nil capabilities to assume every API is served")
+	}
+}
@@ -11,4 +11,13 @@ var (
 	LabelName                    = "name"
 	LabelCloud                   = "cloud"
 	LabelVendor                  = "vendor"
+
+	// LabelClusterSet is the label that adds a ManagedCluster to a ManagedClusterSet.
+	LabelClusterSet = "cluster.open-cluster-management.io/clusterset"
+
+	/*
+		LabelCreatedBy is set on the resources created to import a DiscoveredCluster. Its value is the UID of the
+		DiscoveredCluster, so that only resources created by the discovery operator are cleaned up.
+	*/
+	LabelCreatedBy = "discovery.open-cluster-management.io/created-by"
 )